@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/server/deps"
+	"demondoof-backend/pkg/config"
+	"demondoof-backend/pkg/db"
+	"demondoof-backend/pkg/logger"
+)
+
+const usage = `matchtool works with portable match export files.
+
+Usage:
+  matchtool export   -match <id> [-out file]   Export a match from the database
+  matchtool import   -in <file>                Import an export into the database
+  matchtool validate -in <file> [-db]          Re-simulate an export and check its outcome
+  matchtool simulate -in <file>                Re-simulate an export and print every action
+
+-in and -out default to stdin and stdout. export, import and validate -db read
+DATABASE_URL from the environment or .env.dev like the server. validate -db also
+checks the shipped abilities against the ones stored for that catalog version.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Logs go to stderr so exports can be piped
+	log := logger.New(slog.LevelWarn)
+	log.SetDefault()
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	case "validate":
+		err = runValidate(os.Args[2:], false)
+	case "simulate":
+		err = runValidate(os.Args[2:], true)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "matchtool:", err)
+		os.Exit(1)
+	}
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	matchFlag := fs.String("match", "", "ID of the match to export")
+	out := fs.String("out", "", "file to write (default stdout)")
+	fs.Parse(args)
+
+	matchID, err := uuid.Parse(*matchFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -match: %w", err)
+	}
+
+	gameService, closeDB, err := connect()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	doc, err := gameService.Export(ctx, matchID)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return doc.Encode(w)
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	in := fs.String("in", "", "export file to read (default stdin)")
+	fs.Parse(args)
+
+	doc, err := readExport(*in)
+	if err != nil {
+		return err
+	}
+
+	gameService, closeDB, err := connect()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := gameService.Import(ctx, doc); err != nil {
+		return err
+	}
+
+	fmt.Printf("imported match %s (%d actions)\n", doc.Match.ID, len(doc.Actions))
+	return nil
+}
+
+// runValidate re-simulates an export offline; verbose prints every action
+func runValidate(args []string, verbose bool) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	in := fs.String("in", "", "export file to read (default stdin)")
+	checkDB := fs.Bool("db", false, "compare the shipped abilities with the catalog stored in the database")
+	fs.Parse(args)
+
+	doc, err := readExport(*in)
+	if err != nil {
+		return err
+	}
+
+	if *checkDB {
+		if err := checkCatalog(doc); err != nil {
+			return err
+		}
+	} else if doc.CatalogChanged() {
+		fmt.Fprintf(os.Stderr, "warning: match was played with catalog %s but the export ships %s\n", doc.Match.CatalogVersion, doc.Catalog.Version)
+	}
+
+	state, results, err := doc.Validate()
+	if verbose && results != nil {
+		for i, result := range results {
+			entry := doc.Actions[i]
+			fmt.Printf("#%d turn %d %s %s ap=%d", entry.Seq, result.TurnNo, entry.UserID, entry.ActionType, result.APSpent)
+			for _, hit := range result.Hits {
+				fmt.Printf(" hit(%s dmg=%d hp=%d)", hit.UserID, hit.Damage, hit.HPLeft)
+			}
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("match %s: %d actions, turn %d, status %s", doc.Match.ID, len(results), state.TurnNo, state.Status)
+	if state.Result != nil {
+		fmt.Printf(", end reason %s", state.Result.Reason)
+		if state.Result.WinnerUserID != nil {
+			fmt.Printf(", winner %s", state.Result.WinnerUserID)
+		}
+		if state.Result.WinnerTeam != nil {
+			fmt.Printf(", winning team %d", *state.Result.WinnerTeam)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// checkCatalog refuses an export whose shipped abilities the database would
+// not import as they are
+func checkCatalog(doc *game.Export) error {
+	gameService, closeDB, err := connect()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return gameService.CheckCatalog(ctx, doc)
+}
+
+func readExport(path string) (*game.Export, error) {
+	r := io.Reader(os.Stdin)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return game.Decode(r)
+}
+
+// connect bootstraps the game service against the configured database
+func connect() (*game.Service, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	pool, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	d, err := deps.Bootstrap(pool, &cfg.Config)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+
+	return d.GameService, pool.Close, nil
+}
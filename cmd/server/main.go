@@ -39,6 +39,18 @@ func main() {
 	// Create server
 	srv := server.New(pool, &cfg.Config)
 
+	// Resume matches interrupted by the last shutdown before accepting players
+	recoverCtx, cancelRecover := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := srv.RecoverMatches(recoverCtx); err != nil {
+		slog.Error("Failed to recover active matches", "error", err)
+	}
+	cancelRecover()
+
+	// Background jobs stop with the server
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	srv.StartJobs(jobsCtx)
+
 	// Start server in a goroutine
 	go func() {
 		slog.Info("Server starting", "port", cfg.Port)
@@ -48,19 +60,27 @@ func main() {
 		}
 	}()
 
+	// Metrics stay off the public port
+	go func() {
+		if err := srv.StartMetrics(); err != nil {
+			slog.Error("Metrics server error", "error", err)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	slog.Info("Server shutting down...")
+	stopJobs()
 
 	// Create a deadline for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	// Shutdown server gracefully
-	if err := srv.GetApp().ShutdownWithContext(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 	} else {
 		slog.Info("Server exited gracefully")
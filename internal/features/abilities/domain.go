@@ -0,0 +1,44 @@
+package abilities
+
+import (
+	"errors"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+)
+
+// Ability domain model
+type Ability struct {
+	ID                    string            `json:"id" db:"id"`
+	Name                  string            `json:"name" db:"name"`
+	BaseDamage            int               `json:"base_damage" db:"base_damage"`
+	APCost                int               `json:"ap_cost" db:"ap_cost"`
+	Range                 int               `json:"range" db:"range"`
+	AOERadius             *int              `json:"aoe_radius" db:"aoe_radius"`
+	AOEShape              string            `json:"aoe_shape" db:"aoe_shape"`
+	PerTurnLimit          *int              `json:"per_turn_limit" db:"per_turn_limit"`
+	PerTargetPerTurnLimit *int              `json:"per_target_per_turn_limit" db:"per_target_per_turn_limit"`
+	Effects               []game.EffectSpec `json:"effects" db:"effects"`
+	CreatedAt             time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Spec converts the catalog entry into the rules used by the game engine
+func (a *Ability) Spec() game.Ability {
+	return game.Ability{
+		ID:                    a.ID,
+		Name:                  a.Name,
+		BaseDamage:            a.BaseDamage,
+		APCost:                a.APCost,
+		Range:                 a.Range,
+		AOERadius:             a.AOERadius,
+		AOEShape:              game.AOEShape(a.AOEShape),
+		PerTurnLimit:          a.PerTurnLimit,
+		PerTargetPerTurnLimit: a.PerTargetPerTurnLimit,
+		Effects:               a.Effects,
+	}
+}
+
+var (
+	ErrAbilityNotFound = errors.New("ability not found")
+	ErrVersionNotFound = errors.New("catalog version not found")
+)
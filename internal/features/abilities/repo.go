@@ -0,0 +1,95 @@
+package abilities
+
+import (
+	"context"
+	"errors"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AbilityRepository interface for data access
+type AbilityRepository interface {
+	List(ctx context.Context) ([]*Ability, error)
+	GetByID(ctx context.Context, id string) (*Ability, error)
+	SaveVersion(ctx context.Context, version string, specs []game.Ability) error
+	GetVersion(ctx context.Context, version string) ([]game.Ability, error)
+}
+
+// PostgresAbilityRepository implements AbilityRepository
+type PostgresAbilityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL ability repository
+func NewRepository(pool *pgxpool.Pool) AbilityRepository {
+	return &PostgresAbilityRepository{pool: pool}
+}
+
+const abilityColumns = `id, name, base_damage, ap_cost, range, aoe_radius, aoe_shape, per_turn_limit, per_target_per_turn_limit, effects, created_at`
+
+func (r *PostgresAbilityRepository) List(ctx context.Context) ([]*Ability, error) {
+	query := `SELECT ` + abilityColumns + ` FROM abilities ORDER BY id`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Ability
+	for rows.Next() {
+		ability, err := scanAbility(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ability)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *PostgresAbilityRepository) GetByID(ctx context.Context, id string) (*Ability, error) {
+	query := `SELECT ` + abilityColumns + ` FROM abilities WHERE id = $1`
+	ability, err := scanAbility(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAbilityNotFound
+		}
+		return nil, err
+	}
+
+	return ability, nil
+}
+
+// SaveVersion stores the abilities of a catalog version; a version that is
+// already stored is left as it is
+func (r *PostgresAbilityRepository) SaveVersion(ctx context.Context, version string, specs []game.Ability) error {
+	query := `INSERT INTO ability_catalog_versions (version, abilities) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING`
+	_, err := r.pool.Exec(ctx, query, version, specs)
+	return err
+}
+
+// GetVersion returns the abilities of a stored catalog version
+func (r *PostgresAbilityRepository) GetVersion(ctx context.Context, version string) ([]game.Ability, error) {
+	var specs []game.Ability
+	err := r.pool.QueryRow(ctx, `SELECT abilities FROM ability_catalog_versions WHERE version = $1`, version).Scan(&specs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return specs, nil
+}
+
+func scanAbility(row pgx.Row) (*Ability, error) {
+	var a Ability
+	err := row.Scan(&a.ID, &a.Name, &a.BaseDamage, &a.APCost, &a.Range,
+		&a.AOERadius, &a.AOEShape, &a.PerTurnLimit, &a.PerTargetPerTurnLimit, &a.Effects, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,164 @@
+package abilities
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"demondoof-backend/internal/features/game"
+)
+
+// Service serves the ability catalog from an in-memory cache
+type Service struct {
+	repo AbilityRepository
+
+	mu      sync.RWMutex
+	loaded  bool
+	list    []*Ability
+	byID    map[string]*Ability
+	version string
+}
+
+// NewService creates a new ability catalog service
+func NewService(repo AbilityRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Refresh reloads the catalog from the database, recomputes its version and
+// stores the abilities of that version so matches played with it can be
+// replayed after the catalog changes
+func (s *Service) Refresh(ctx context.Context) error {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	// The version is a content hash so it only changes when the catalog does
+	encoded, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	version := hex.EncodeToString(sum[:8])
+
+	byID := make(map[string]*Ability, len(list))
+	specs := make([]game.Ability, 0, len(list))
+	for _, a := range list {
+		byID[a.ID] = a
+		specs = append(specs, a.Spec())
+	}
+	if err := s.repo.SaveVersion(ctx, version, specs); err != nil {
+		return fmt.Errorf("failed to store catalog version: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.list = list
+	s.byID = byID
+	s.version = version
+	s.loaded = true
+
+	return nil
+}
+
+// ensureLoaded fills the cache on first use
+func (s *Service) ensureLoaded(ctx context.Context) error {
+	s.mu.RLock()
+	loaded := s.loaded
+	s.mu.RUnlock()
+
+	if loaded {
+		return nil
+	}
+	return s.Refresh(ctx)
+}
+
+// List returns every ability along with the catalog version
+func (s *Service) List(ctx context.Context) ([]*Ability, string, error) {
+	if err := s.ensureLoaded(ctx); err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list, s.version, nil
+}
+
+// GetByID returns a single ability along with the catalog version
+func (s *Service) GetByID(ctx context.Context, id string) (*Ability, string, error) {
+	if err := s.ensureLoaded(ctx); err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ability, ok := s.byID[id]
+	if !ok {
+		return nil, "", ErrAbilityNotFound
+	}
+	return ability, s.version, nil
+}
+
+// Specs returns the catalog as game engine rules keyed by ability ID
+func (s *Service) Specs(ctx context.Context) (map[string]game.Ability, string, error) {
+	if err := s.ensureLoaded(ctx); err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	specs := make(map[string]game.Ability, len(s.list))
+	for _, a := range s.list {
+		specs[a.ID] = a.Spec()
+	}
+	return specs, s.version, nil
+}
+
+// SpecsAt returns the game engine rules of a catalog version, which may be an
+// earlier one than the current catalog
+func (s *Service) SpecsAt(ctx context.Context, version string) (map[string]game.Ability, error) {
+	if version == "" {
+		return nil, game.ErrCatalogVersionMissing
+	}
+	if current, currentVersion, err := s.Specs(ctx); err == nil && currentVersion == version {
+		return current, nil
+	}
+
+	list, err := s.repo.GetVersion(ctx, version)
+	if errors.Is(err, ErrVersionNotFound) {
+		return nil, fmt.Errorf("%w: %s", game.ErrCatalogVersionMissing, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	specs := make(map[string]game.Ability, len(list))
+	for _, a := range list {
+		specs[a.ID] = a
+	}
+	return specs, nil
+}
+
+// SaveSpecs stores the abilities of a catalog version that was not loaded
+// here, such as the one shipped with an imported match
+func (s *Service) SaveSpecs(ctx context.Context, version string, specs map[string]game.Ability) error {
+	ids := make([]string, 0, len(specs))
+	for id := range specs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	list := make([]game.Ability, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, specs[id])
+	}
+	if err := s.repo.SaveVersion(ctx, version, list); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
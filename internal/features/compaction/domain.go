@@ -0,0 +1,20 @@
+package compaction
+
+// Report summarizes what a compaction run reclaimed
+type Report struct {
+	Matches       int   `json:"matches"`
+	CharacterRows int64 `json:"character_rows"`
+	AbilityRows   int64 `json:"ability_rows"`
+}
+
+// Rows returns the total number of snapshot rows deleted
+func (r Report) Rows() int64 {
+	return r.CharacterRows + r.AbilityRows
+}
+
+// add folds the outcome of one match into the report
+func (r *Report) add(other Report) {
+	r.Matches += other.Matches
+	r.CharacterRows += other.CharacterRows
+	r.AbilityRows += other.AbilityRows
+}
@@ -0,0 +1,92 @@
+package compaction
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CompactionRepository interface for snapshot compaction
+type CompactionRepository interface {
+	ListCompactable(ctx context.Context, endedBefore time.Time, limit int) ([]uuid.UUID, error)
+	CompactMatch(ctx context.Context, matchID uuid.UUID) (Report, error)
+}
+
+// PostgresCompactionRepository implements CompactionRepository
+type PostgresCompactionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL compaction repository
+func NewRepository(pool *pgxpool.Pool) CompactionRepository {
+	return &PostgresCompactionRepository{pool: pool}
+}
+
+// ListCompactable returns ended matches older than endedBefore that still
+// carry every turn's snapshots, oldest first
+func (r *PostgresCompactionRepository) ListCompactable(ctx context.Context, endedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	query := `SELECT id FROM matches
+		WHERE status = 'ended' AND compacted_at IS NULL AND ended_at < $1
+		ORDER BY ended_at
+		LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, endedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CompactMatch deletes every snapshot of a match except those of its first
+// and last snapshotted turn, and marks the match as compacted. match_actions
+// is left untouched so the match can still be rebuilt.
+func (r *PostgresCompactionRepository) CompactMatch(ctx context.Context, matchID uuid.UUID) (Report, error) {
+	report := Report{Matches: 1}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var first, last *int
+	query := `SELECT MIN(turn_no), MAX(turn_no) FROM character_snapshots WHERE match_id = $1`
+	if err := tx.QueryRow(ctx, query, matchID).Scan(&first, &last); err != nil {
+		return Report{}, err
+	}
+
+	if first != nil {
+		tag, err := tx.Exec(ctx, `DELETE FROM character_snapshots WHERE match_id = $1 AND turn_no > $2 AND turn_no < $3`, matchID, *first, *last)
+		if err != nil {
+			return Report{}, err
+		}
+		report.CharacterRows = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM ability_snapshots WHERE match_id = $1 AND turn_no > $2 AND turn_no < $3`, matchID, *first, *last)
+		if err != nil {
+			return Report{}, err
+		}
+		report.AbilityRows = tag.RowsAffected()
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE matches SET compacted_at = NOW() WHERE id = $1`, matchID); err != nil {
+		return Report{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
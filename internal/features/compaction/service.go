@@ -0,0 +1,86 @@
+package compaction
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Compaction metrics, published on /debug/vars
+var (
+	runsTotal          = expvar.NewInt("snapshot_compaction_runs")
+	rowsReclaimedTotal = expvar.NewInt("snapshot_rows_reclaimed")
+)
+
+// Config controls when compaction runs and what it keeps
+type Config struct {
+	// Interval between runs; zero disables the job
+	Interval time.Duration
+	// Retention is how long after ending a match keeps every turn's snapshots
+	Retention time.Duration
+	// BatchSize caps the matches compacted per run
+	BatchSize int
+}
+
+// Service compacts the snapshots of old ended matches in the background
+type Service struct {
+	repo CompactionRepository
+	cfg  Config
+}
+
+// NewService creates a new snapshot compaction service
+func NewService(repo CompactionRepository, cfg Config) *Service {
+	return &Service{repo: repo, cfg: cfg}
+}
+
+// Run compacts on every interval until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		slog.Info("Snapshot compaction disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CompactOnce(ctx); err != nil {
+				slog.Error("Snapshot compaction failed", "error", err)
+			}
+		}
+	}
+}
+
+// CompactOnce compacts one batch of ended matches past the retention period
+// and reports how many rows it reclaimed
+func (s *Service) CompactOnce(ctx context.Context) (Report, error) {
+	started := time.Now()
+	ids, err := s.repo.ListCompactable(ctx, started.Add(-s.cfg.Retention), s.cfg.BatchSize)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list compactable matches: %w", err)
+	}
+
+	var report Report
+	for _, matchID := range ids {
+		matchReport, err := s.repo.CompactMatch(ctx, matchID)
+		if err != nil {
+			slog.Error("Failed to compact match snapshots", "error", err, "matchId", matchID)
+			continue
+		}
+		report.add(matchReport)
+	}
+
+	runsTotal.Add(1)
+	rowsReclaimedTotal.Add(report.Rows())
+	slog.Info("Snapshot compaction finished",
+		"matches", report.Matches, "characterRows", report.CharacterRows, "abilityRows", report.AbilityRows,
+		"rowsReclaimed", report.Rows(), "duration", time.Since(started))
+
+	return report, nil
+}
@@ -0,0 +1,94 @@
+package game
+
+// AOEShape is the footprint of an area ability around its target tile
+type AOEShape string
+
+const (
+	// ShapeDiamond covers tiles within aoe_radius Manhattan distance
+	ShapeDiamond AOEShape = "diamond"
+	// ShapeSquare covers tiles within aoe_radius in both axes
+	ShapeSquare AOEShape = "square"
+	// ShapeLine covers the target and aoe_radius tiles behind it, away from the caster
+	ShapeLine AOEShape = "line"
+	// ShapeCone widens by one tile per side for every step behind the target
+	ShapeCone AOEShape = "cone"
+)
+
+// AffectedTiles returns the walkable tiles hit by an ability aimed from one
+// tile at another, in a deterministic order. Single target abilities only
+// affect the target tile.
+func AffectedTiles(board *Board, ability Ability, from, target Position) []Position {
+	if ability.AOERadius == nil {
+		return []Position{target}
+	}
+	r := *ability.AOERadius
+
+	var candidates []Position
+	switch ability.AOEShape {
+	case ShapeSquare:
+		for y := target.Y - r; y <= target.Y+r; y++ {
+			for x := target.X - r; x <= target.X+r; x++ {
+				candidates = append(candidates, Position{X: x, Y: y})
+			}
+		}
+	case ShapeLine, ShapeCone:
+		dir := castDirection(from, target)
+		side := Position{X: -dir.Y, Y: dir.X}
+		for step := 0; step <= r; step++ {
+			centre := Position{X: target.X + dir.X*step, Y: target.Y + dir.Y*step}
+			width := 0
+			if ability.AOEShape == ShapeCone {
+				width = step
+			}
+			for w := -width; w <= width; w++ {
+				candidates = append(candidates, Position{X: centre.X + side.X*w, Y: centre.Y + side.Y*w})
+			}
+			// Without a direction the shape collapses onto the target
+			if dir == (Position{}) {
+				break
+			}
+		}
+	default:
+		for y := target.Y - r; y <= target.Y+r; y++ {
+			for x := target.X - r; x <= target.X+r; x++ {
+				p := Position{X: x, Y: y}
+				if p.Distance(target) <= r {
+					candidates = append(candidates, p)
+				}
+			}
+		}
+	}
+
+	tiles := candidates[:0]
+	for _, p := range candidates {
+		if board.IsWalkable(p) {
+			tiles = append(tiles, p)
+		}
+	}
+	return tiles
+}
+
+// castDirection returns the unit step along the dominant axis from caster to
+// target, preferring the horizontal axis on diagonals
+func castDirection(from, target Position) Position {
+	dx, dy := target.X-from.X, target.Y-from.Y
+	switch {
+	case dx == 0 && dy == 0:
+		return Position{}
+	case abs(dx) >= abs(dy):
+		return Position{X: sign(dx)}
+	default:
+		return Position{Y: sign(dy)}
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAffectedTiles(t *testing.T) {
+	area := func(shape AOEShape, radius int) Ability {
+		return Ability{ID: "area", AOEShape: shape, AOERadius: &radius}
+	}
+
+	tests := []struct {
+		name         string
+		board        *Board
+		ability      Ability
+		from, target Position
+		want         []Position
+	}{
+		{
+			name:    "single target",
+			board:   NewBoard(5, 5),
+			ability: Ability{ID: "bolt"},
+			from:    Position{X: 0, Y: 0},
+			target:  Position{X: 3, Y: 3},
+			want:    []Position{{X: 3, Y: 3}},
+		},
+		{
+			name:    "diamond",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeDiamond, 1),
+			from:    Position{X: 0, Y: 2},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}, {X: 2, Y: 3}},
+		},
+		{
+			name:    "no shape is a diamond",
+			board:   NewBoard(5, 5),
+			ability: area("", 1),
+			from:    Position{X: 0, Y: 2},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}, {X: 2, Y: 3}},
+		},
+		{
+			name: "diamond skips walls",
+			board: testBoard(
+				"...",
+				"#..",
+				".#.",
+			),
+			ability: area(ShapeDiamond, 1),
+			from:    Position{X: 2, Y: 0},
+			target:  Position{X: 1, Y: 1},
+			want:    []Position{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}},
+		},
+		{
+			name:    "square",
+			board:   NewBoard(3, 3),
+			ability: area(ShapeSquare, 1),
+			from:    Position{X: 0, Y: 0},
+			target:  Position{X: 1, Y: 1},
+			want: []Position{
+				{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0},
+				{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1},
+				{X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2},
+			},
+		},
+		{
+			name:    "square clipped by the board edge",
+			board:   NewBoard(3, 3),
+			ability: area(ShapeSquare, 1),
+			from:    Position{X: 2, Y: 2},
+			target:  Position{X: 0, Y: 0},
+			want:    []Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+		},
+		{
+			name:    "horizontal line",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeLine, 2),
+			from:    Position{X: 0, Y: 2},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}},
+		},
+		{
+			name:    "vertical line",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeLine, 2),
+			from:    Position{X: 2, Y: 0},
+			target:  Position{X: 2, Y: 1},
+			want:    []Position{{X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3}},
+		},
+		{
+			name:    "diagonal line runs horizontally",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeLine, 2),
+			from:    Position{X: 0, Y: 0},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}},
+		},
+		{
+			name:    "line clipped by the board edge",
+			board:   NewBoard(5, 1),
+			ability: area(ShapeLine, 2),
+			from:    Position{X: 0, Y: 0},
+			target:  Position{X: 4, Y: 0},
+			want:    []Position{{X: 4, Y: 0}},
+		},
+		{
+			name:    "line aimed at the caster's tile",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeLine, 2),
+			from:    Position{X: 2, Y: 2},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 2}},
+		},
+		{
+			name:    "cone",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeCone, 1),
+			from:    Position{X: 0, Y: 2},
+			target:  Position{X: 1, Y: 2},
+			want:    []Position{{X: 1, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3}},
+		},
+		{
+			name:    "cone aimed up",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeCone, 1),
+			from:    Position{X: 2, Y: 4},
+			target:  Position{X: 2, Y: 3},
+			want:    []Position{{X: 2, Y: 3}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}},
+		},
+		{
+			name:    "cone aimed at the caster's tile",
+			board:   NewBoard(5, 5),
+			ability: area(ShapeCone, 2),
+			from:    Position{X: 2, Y: 2},
+			target:  Position{X: 2, Y: 2},
+			want:    []Position{{X: 2, Y: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AffectedTiles(tt.board, tt.ability, tt.from, tt.target)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AffectedTiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+import "fmt"
+
+// Terrain is the kind of a single board tile
+type Terrain string
+
+const (
+	TerrainFloor Terrain = "floor"
+	TerrainRough Terrain = "rough"
+	TerrainWall  Terrain = "wall"
+)
+
+// defaultMoveCosts is the AP needed to step onto each walkable terrain
+var defaultMoveCosts = map[Terrain]int{
+	TerrainFloor: 1,
+	TerrainRough: 2,
+}
+
+// Board is a rectangular grid of tiles stored row-major
+type Board struct {
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	Tiles  []Terrain `json:"tiles"`
+
+	// MoveCosts optionally overrides the AP cost of entering a terrain
+	MoveCosts map[Terrain]int `json:"move_costs,omitempty"`
+}
+
+// NewBoard creates an empty board filled with floor tiles
+func NewBoard(width, height int) *Board {
+	tiles := make([]Terrain, width*height)
+	for i := range tiles {
+		tiles[i] = TerrainFloor
+	}
+	return &Board{Width: width, Height: height, Tiles: tiles}
+}
+
+// DefaultBoard returns the standard 10x10 arena with a few cover walls
+func DefaultBoard() *Board {
+	b := NewBoard(10, 10)
+	for _, p := range []Position{
+		{X: 3, Y: 3}, {X: 3, Y: 4}, {X: 6, Y: 5}, {X: 6, Y: 6},
+		{X: 4, Y: 7}, {X: 5, Y: 2},
+	} {
+		b.Set(p, TerrainWall)
+	}
+	for _, p := range []Position{{X: 4, Y: 4}, {X: 5, Y: 4}, {X: 4, Y: 5}, {X: 5, Y: 5}} {
+		b.Set(p, TerrainRough)
+	}
+	return b
+}
+
+// Validate checks the move cost overrides. Every step must cost at least 1 AP,
+// or the distance heuristic of FindPath would overestimate and miss the
+// cheapest route.
+func (b *Board) Validate() error {
+	for terrain, cost := range b.MoveCosts {
+		if cost < 1 {
+			return fmt.Errorf("%w: %s costs %d", ErrInvalidMoveCost, terrain, cost)
+		}
+	}
+	return nil
+}
+
+// InBounds reports whether the position lies on the board
+func (b *Board) InBounds(p Position) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < b.Width && p.Y < b.Height
+}
+
+// At returns the terrain of a tile, treating out of bounds as wall
+func (b *Board) At(p Position) Terrain {
+	if !b.InBounds(p) {
+		return TerrainWall
+	}
+	return b.Tiles[p.Y*b.Width+p.X]
+}
+
+// Set changes the terrain of a tile
+func (b *Board) Set(p Position, t Terrain) {
+	if b.InBounds(p) {
+		b.Tiles[p.Y*b.Width+p.X] = t
+	}
+}
+
+// IsWalkable reports whether a character may stand on the tile
+func (b *Board) IsWalkable(p Position) bool {
+	return b.At(p) != TerrainWall
+}
+
+// StepCost returns the AP needed to step onto the tile
+func (b *Board) StepCost(p Position) int {
+	t := b.At(p)
+	if cost, ok := b.MoveCosts[t]; ok {
+		return cost
+	}
+	if cost, ok := defaultMoveCosts[t]; ok {
+		return cost
+	}
+	return 1
+}
+
+// SpawnPoints returns n distinct walkable start tiles. Even indexes start on
+// the left edge and odd ones on the right, filling each edge from the middle
+// row outwards, so alternating seats face each other.
+func (b *Board) SpawnPoints(n int) []Position {
+	columns := [2]int{1, b.Width - 2}
+	var edges [2][]Position
+	for side, x := range columns {
+		for i := 0; i < b.Height; i++ {
+			// Middle row first, then one above, one below and so on
+			offset := (i + 1) / 2
+			if i%2 == 1 {
+				offset = -offset
+			}
+			p := Position{X: x, Y: b.Height/2 + offset}
+			if b.InBounds(p) && b.IsWalkable(p) {
+				edges[side] = append(edges[side], p)
+			}
+		}
+	}
+
+	points := make([]Position, 0, n)
+	for i := 0; i < n; i++ {
+		edge := edges[i%2]
+		if i/2 >= len(edge) {
+			break
+		}
+		points = append(points, edge[i/2])
+	}
+	return points
+}
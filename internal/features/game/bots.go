@@ -0,0 +1,181 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// botThinkTime is how long a bot waits before playing its turn
+	botThinkTime = 1500 * time.Millisecond
+	// maxBotActions caps the actions a bot takes in one turn
+	maxBotActions = 8
+)
+
+// scheduleBot plays the current turn after a short pause when it belongs to
+// a bot. The caller must hold the match lock.
+func (s *Service) scheduleBot(lm *liveMatch) {
+	active := lm.state.ActiveCharacter()
+	if !active.IsBot || lm.state.Status != StatusActive {
+		return
+	}
+
+	matchID, turnNo := lm.state.MatchID, lm.state.TurnNo
+	time.AfterFunc(botThinkTime, func() {
+		s.playBot(matchID, turnNo)
+	})
+}
+
+// playBot takes a bot's turn: it casts at the weakest enemy it can hit,
+// closes in when nothing is in reach and ends the turn. Bot actions go
+// through the same path as player actions, so they are logged and replayed
+// like any other.
+func (s *Service) playBot(matchID uuid.UUID, turnNo int) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	botID := lm.state.ActiveUserID()
+	moved := false
+	for i := 0; i < maxBotActions; i++ {
+		if lm.state.Status != StatusActive || lm.state.TurnNo != turnNo {
+			return
+		}
+
+		action, ok := botCast(lm.state, botID)
+		if !ok && !moved {
+			action, ok = botMove(lm.state, botID)
+			moved = true
+		}
+		if !ok {
+			break
+		}
+		if _, err := s.apply(ctx, lm, action); err != nil {
+			slog.Warn("Bot action failed", "error", err, "matchId", matchID, "turnNo", turnNo, "userId", botID)
+			break
+		}
+	}
+
+	if lm.state.Status != StatusActive || lm.state.TurnNo != turnNo {
+		return
+	}
+	if _, err := s.apply(ctx, lm, Action{Type: ActionEndTurn, UserID: botID}); err != nil {
+		// The turn timer still ends the turn
+		slog.Warn("Bot failed to end its turn", "error", err, "matchId", matchID, "turnNo", turnNo, "userId", botID)
+	}
+}
+
+// botCast picks the most damaging legal cast at the weakest enemy in reach.
+// Casts whose area would also catch a teammate are passed over.
+func botCast(state *MatchState, botID uuid.UUID) (Action, bool) {
+	bot := state.Characters[botID]
+
+	ids := make([]string, 0, len(state.Abilities))
+	for id := range state.Abilities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := state.Abilities[ids[i]], state.Abilities[ids[j]]
+		if a.BaseDamage != b.BaseDamage {
+			return a.BaseDamage > b.BaseDamage
+		}
+		return a.ID < b.ID
+	})
+
+	for _, id := range ids {
+		ability := state.Abilities[id]
+		if ability.APCost > bot.AP {
+			continue
+		}
+
+		var best *Character
+		for _, tile := range LegalTargets(state, bot, ability, bot.Position()) {
+			target := state.CharacterAt(tile)
+			if target == nil || target.UserID == botID || !target.IsAlive() || bot.IsAlly(target) {
+				continue
+			}
+			if hitsAlly(state, bot, ability, tile) {
+				continue
+			}
+			if best == nil || target.HP < best.HP {
+				best = target
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		action := Action{Type: ActionCast, UserID: botID, AbilityID: id, Target: best.Position()}
+		// Usage limits are only known to the engine, so try the cast on a copy
+		if _, err := ApplyAction(state.Clone(), action); err == nil {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// botMove walks as far towards the nearest enemy as the bot's AP allows
+func botMove(state *MatchState, botID uuid.UUID) (Action, bool) {
+	bot := state.Characters[botID]
+
+	var (
+		route []Position
+		found bool
+	)
+	for _, enemy := range state.Alive() {
+		if enemy.UserID == botID || bot.IsAlly(enemy) {
+			continue
+		}
+		// The enemy's own tile is the goal, so only other characters block
+		blocked := func(p Position) bool {
+			c := state.CharacterAt(p)
+			return c != nil && c != bot && c != enemy
+		}
+		path, _, ok := FindPath(state.Board, bot.Position(), enemy.Position(), blocked)
+		if !ok || len(path) < 2 {
+			continue
+		}
+		if !found || len(path) < len(route) {
+			route, found = path[:len(path)-1], true
+		}
+	}
+	if !found {
+		return Action{}, false
+	}
+
+	spent, steps := 0, 0
+	for _, p := range route {
+		if spent+state.Board.StepCost(p) > bot.AP {
+			break
+		}
+		spent += state.Board.StepCost(p)
+		steps++
+	}
+	if steps == 0 {
+		return Action{}, false
+	}
+
+	return Action{Type: ActionMove, UserID: botID, Target: route[steps-1], Path: route[:steps]}, true
+}
+
+// hitsAlly reports whether a cast at the tile would catch one of the bot's
+// teammates
+func hitsAlly(state *MatchState, bot *Character, ability Ability, tile Position) bool {
+	for _, c := range castTargets(state, bot, ability, tile) {
+		if bot.IsAlly(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package game
+
+import "github.com/google/uuid"
+
+// Values of matches.end_reason
+const (
+	EndReasonElimination    EndReason = "elimination"
+	EndReasonSurrender      EndReason = "surrender"
+	EndReasonTimeoutForfeit EndReason = "timeout_forfeit"
+	EndReasonAbandoned      EndReason = "abandoned"
+	EndReasonTurnCapDraw    EndReason = "turn_cap_draw"
+	EndReasonMutualDraw     EndReason = "mutual_draw"
+)
+
+// EndCondition inspects the state after an action and returns the result
+// when the match is over, or nil to let it continue
+type EndCondition func(state *MatchState) *MatchResult
+
+// DefaultConditions are checked, in order, for matches that do not set their own
+var DefaultConditions = []EndCondition{
+	LastStanding,
+	MutualDraw,
+	TurnCap,
+}
+
+// LastStanding ends the match once at most one character, or one team, is
+// still in the fight. The reason is the forfeit that removed the last loser
+// when the ending action was one, and elimination otherwise, even if other
+// players forfeited earlier.
+func LastStanding(state *MatchState) *MatchResult {
+	alive := state.Alive()
+	for _, c := range alive[min(1, len(alive)):] {
+		if !c.IsAlly(alive[0]) {
+			return nil
+		}
+	}
+
+	result := &MatchResult{Reason: EndReasonElimination}
+	if state.pendingForfeit != "" {
+		result.Reason = state.pendingForfeit
+	}
+	switch {
+	case len(alive) == 0:
+	case alive[0].Team != 0:
+		team := alive[0].Team
+		result.WinnerTeam = &team
+	default:
+		result.WinnerUserID = &alive[0].UserID
+	}
+	return result
+}
+
+// MutualDraw ends the match in a draw once every player agreed to it
+func MutualDraw(state *MatchState) *MatchResult {
+	if !state.DrawAgreed {
+		return nil
+	}
+	return &MatchResult{Reason: EndReasonMutualDraw}
+}
+
+// TurnCap ends the match in a draw once the turn limit is exceeded
+func TurnCap(state *MatchState) *MatchResult {
+	if state.Rules.MaxTurns <= 0 || state.TurnNo <= state.Rules.MaxTurns {
+		return nil
+	}
+	return &MatchResult{Reason: EndReasonTurnCapDraw}
+}
+
+// checkEnd runs the end conditions and closes the match on the first that
+// fires, reporting whether the match is over
+func (s *MatchState) checkEnd() bool {
+	defer func() { s.pendingForfeit = "" }()
+
+	if s.Status != StatusActive {
+		return s.Status == StatusEnded
+	}
+
+	conditions := s.Conditions
+	if conditions == nil {
+		conditions = DefaultConditions
+	}
+
+	for _, condition := range conditions {
+		if result := condition(s); result != nil {
+			s.end(result)
+			return true
+		}
+	}
+	return false
+}
+
+// forfeitCharacter removes a character from the fight for a non combat reason
+func (s *MatchState) forfeitCharacter(c *Character, reason EndReason) {
+	c.Forfeited = true
+	c.ForfeitReason = reason
+	s.pendingForfeit = reason
+}
+
+// PlayerSummary is a participant's standing when the match ended
+type PlayerSummary struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Team          int       `json:"team,omitempty"`
+	HP            int       `json:"hp"`
+	Alive         bool      `json:"alive"`
+	ForfeitReason EndReason `json:"forfeit_reason,omitempty"`
+}
+
+// Summary lists every participant's final standing in turn order
+func (s *MatchState) Summary() []PlayerSummary {
+	summary := make([]PlayerSummary, 0, len(s.TurnOrder))
+	for _, id := range s.TurnOrder {
+		c := s.Characters[id]
+		summary = append(summary, PlayerSummary{
+			UserID:        c.UserID,
+			Team:          c.Team,
+			HP:            c.HP,
+			Alive:         c.IsAlive(),
+			ForfeitReason: c.ForfeitReason,
+		})
+	}
+	return summary
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLastStandingReason(t *testing.T) {
+	// Players by name: a, b and c, and d in team matches
+	type step struct {
+		action ActionType
+		user   string
+		// target names the player to kill when action is a cast
+		target string
+	}
+
+	tests := []struct {
+		name       string
+		teams      map[string]int
+		steps      []step
+		wantReason EndReason
+		wantWinner string
+		wantTeam   int
+	}{
+		{
+			name: "forfeit early, then elimination",
+			steps: []step{
+				{action: ActionSurrender, user: "b"},
+				{action: ActionCast, user: "a", target: "c"},
+			},
+			wantReason: EndReasonElimination,
+			wantWinner: "a",
+		},
+		{
+			name: "timeout forfeit early, then elimination",
+			steps: []step{
+				{action: ActionEndTurn, user: "a"},
+				{action: ActionTimeout, user: "b"},
+				{action: ActionEndTurn, user: "c"},
+				{action: ActionCast, user: "a", target: "c"},
+			},
+			wantReason: EndReasonElimination,
+			wantWinner: "a",
+		},
+		{
+			name: "elimination, then surrender",
+			steps: []step{
+				{action: ActionCast, user: "a", target: "c"},
+				{action: ActionSurrender, user: "b"},
+			},
+			wantReason: EndReasonSurrender,
+			wantWinner: "a",
+		},
+		{
+			name: "elimination, then timeout forfeit",
+			steps: []step{
+				{action: ActionCast, user: "a", target: "c"},
+				{action: ActionEndTurn, user: "a"},
+				{action: ActionTimeout, user: "b"},
+			},
+			wantReason: EndReasonTimeoutForfeit,
+			wantWinner: "a",
+		},
+		{
+			name: "everyone else surrenders",
+			steps: []step{
+				{action: ActionSurrender, user: "b"},
+				{action: ActionSurrender, user: "c"},
+			},
+			wantReason: EndReasonSurrender,
+			wantWinner: "a",
+		},
+		{
+			name:  "team forfeit early, then elimination",
+			teams: map[string]int{"a": 1, "b": 2, "c": 2, "d": 1},
+			steps: []step{
+				{action: ActionSurrender, user: "b"},
+				{action: ActionCast, user: "a", target: "c"},
+			},
+			wantReason: EndReasonElimination,
+			wantTeam:   1,
+		},
+		{
+			name:  "team elimination, then surrender",
+			teams: map[string]int{"a": 1, "b": 2, "c": 2, "d": 1},
+			steps: []step{
+				{action: ActionCast, user: "a", target: "c"},
+				{action: ActionSurrender, user: "b"},
+			},
+			wantReason: EndReasonSurrender,
+			wantTeam:   1,
+		},
+	}
+
+	// a shoots along the middle row; b and d stand out of the way
+	tiles := map[string]Position{"a": {X: 0, Y: 1}, "b": {X: 2, Y: 0}, "c": {X: 4, Y: 1}, "d": {X: 2, Y: 2}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{"a", "b", "c"}
+			if tt.teams != nil {
+				names = append(names, "d")
+			}
+			ids := make(map[string]uuid.UUID, len(names))
+			participants := make([]Participant, 0, len(names))
+			for _, name := range names {
+				ids[name] = uuid.New()
+				participants = append(participants, Participant{
+					UserID: ids[name], StartingHP: 10, StartingAP: 10,
+					StartX: tiles[name].X, StartY: tiles[name].Y, Team: tt.teams[name],
+				})
+			}
+			abilities := map[string]Ability{"smite": {ID: "smite", BaseDamage: 100, APCost: 1, Range: 10}}
+			state, err := NewMatchState(uuid.New(), NewBoard(5, 3), abilities, participants)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state.Seed = 1
+			state.Rules.MaxConsecutiveTimeouts = 1
+
+			for i, s := range tt.steps {
+				action := Action{Type: s.action, UserID: ids[s.user]}
+				if s.action != ActionCast {
+					if _, err := ApplyAction(state, action); err != nil {
+						t.Fatalf("step %d: %v", i+1, err)
+					}
+					continue
+				}
+
+				// Cast until the target falls, rolls may miss
+				action.AbilityID, action.Target = "smite", tiles[s.target]
+				for state.Characters[ids[s.target]].IsAlive() {
+					if _, err := ApplyAction(state, action); err != nil {
+						t.Fatalf("step %d: %v", i+1, err)
+					}
+				}
+			}
+
+			if state.Status != StatusEnded || state.Result == nil {
+				t.Fatalf("match is %s, want ended", state.Status)
+			}
+			if state.Result.Reason != tt.wantReason {
+				t.Errorf("reason = %s, want %s", state.Result.Reason, tt.wantReason)
+			}
+			if tt.wantWinner != "" && (state.Result.WinnerUserID == nil || *state.Result.WinnerUserID != ids[tt.wantWinner]) {
+				t.Errorf("winner = %v, want %s", state.Result.WinnerUserID, tt.wantWinner)
+			}
+			if tt.wantTeam != 0 && (state.Result.WinnerTeam == nil || *state.Result.WinnerTeam != tt.wantTeam) {
+				t.Errorf("winning team = %v, want %d", state.Result.WinnerTeam, tt.wantTeam)
+			}
+		})
+	}
+}
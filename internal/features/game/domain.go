@@ -0,0 +1,443 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchStatus mirrors the matches.status column
+type MatchStatus string
+
+const (
+	StatusPending MatchStatus = "pending"
+	StatusActive  MatchStatus = "active"
+	StatusEnded   MatchStatus = "ended"
+)
+
+// EndReason mirrors the matches.end_reason column
+type EndReason string
+
+// MatchResult is the final outcome of a match; a nil winner is a draw.
+// Team matches are won by a team rather than a player.
+type MatchResult struct {
+	WinnerUserID *uuid.UUID `json:"winner_user_id"`
+	WinnerTeam   *int       `json:"winner_team,omitempty"`
+	Reason       EndReason  `json:"reason"`
+	TurnNo       int        `json:"turn_no"`
+}
+
+// Rules are the per-match settings the engine and turn scheduler enforce
+type Rules struct {
+	TurnTimeoutSec         int `json:"turn_timeout_sec"`
+	MaxConsecutiveTimeouts int `json:"max_consecutive_timeouts"`
+	MaxTurns               int `json:"max_turns"`
+	DrawOfferCooldownTurns int `json:"draw_offer_cooldown_turns"`
+
+	// Spectators see an event once the match is this many turns and seconds past it
+	SpectatorDelayTurns int `json:"spectator_delay_turns"`
+	SpectatorDelaySec   int `json:"spectator_delay_sec"`
+
+	// A disconnected player keeps their slot for DisconnectGraceSec seconds
+	// (zero keeps it forever); TimerPolicy decides whether their turn clock
+	// stops meanwhile. Pausing needs a grace period, or an away player would
+	// stall the match.
+	DisconnectGraceSec int         `json:"disconnect_grace_sec"`
+	TimerPolicy        TimerPolicy `json:"timer_policy"`
+
+	// Map names the board; AllowedAbilities restricts the catalog when set
+	Map              string   `json:"map,omitempty"`
+	AllowedAbilities []string `json:"allowed_abilities,omitempty"`
+}
+
+// IsZero reports whether no rules were set, as for matches stored before
+// rules were
+func (r Rules) IsZero() bool {
+	return reflect.DeepEqual(r, Rules{})
+}
+
+// TimerPolicy is what happens to the turn timer of a disconnected player
+type TimerPolicy string
+
+const (
+	TimerPolicyContinue TimerPolicy = "continue"
+	TimerPolicyPause    TimerPolicy = "pause"
+)
+
+// TurnTimeout returns how long a player has to act before the turn ends
+func (r Rules) TurnTimeout() time.Duration {
+	return time.Duration(r.TurnTimeoutSec) * time.Second
+}
+
+// DisconnectGrace returns how long a disconnected player keeps their slot
+func (r Rules) DisconnectGrace() time.Duration {
+	return time.Duration(r.DisconnectGraceSec) * time.Second
+}
+
+// SpectatorDelay returns the minimum time an event is held back from spectators
+func (r Rules) SpectatorDelay() time.Duration {
+	return time.Duration(r.SpectatorDelaySec) * time.Second
+}
+
+// Position is a tile coordinate on the board
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Distance returns the Manhattan distance between two positions
+func (p Position) Distance(o Position) int {
+	return abs(p.X-o.X) + abs(p.Y-o.Y)
+}
+
+// Ability describes the rules of a castable ability, mirroring the abilities table
+type Ability struct {
+	ID                    string       `json:"id"`
+	Name                  string       `json:"name"`
+	BaseDamage            int          `json:"base_damage"`
+	APCost                int          `json:"ap_cost"`
+	Range                 int          `json:"range"`
+	AOERadius             *int         `json:"aoe_radius,omitempty"`
+	AOEShape              AOEShape     `json:"aoe_shape,omitempty"`
+	Effects               []EffectSpec `json:"effects,omitempty"`
+	PerTurnLimit          *int         `json:"per_turn_limit,omitempty"`
+	PerTargetPerTurnLimit *int         `json:"per_target_per_turn_limit,omitempty"`
+}
+
+// Participant is the starting setup of a player, mirroring match_participants
+type Participant struct {
+	UserID     uuid.UUID `json:"user_id"`
+	IsBot      bool      `json:"is_bot"`
+	StartingHP int       `json:"starting_hp"`
+	StartingAP int       `json:"starting_ap"`
+	StartX     int       `json:"start_x"`
+	StartY     int       `json:"start_y"`
+	// Team groups allies in team matches; zero fights alone
+	Team int `json:"team,omitempty"`
+}
+
+// Character is the live state of a participant, shaped like character_snapshots
+type Character struct {
+	UserID     uuid.UUID `json:"user_id"`
+	IsBot      bool      `json:"is_bot"`
+	HP         int       `json:"hp"`
+	AP         int       `json:"ap"`
+	X          int       `json:"x"`
+	Y          int       `json:"y"`
+	StartingHP int       `json:"starting_hp"`
+	StartingAP int       `json:"starting_ap"`
+	Team       int       `json:"team,omitempty"`
+
+	ConsecutiveTimeouts int       `json:"consecutive_timeouts"`
+	Forfeited           bool      `json:"forfeited"`
+	ForfeitReason       EndReason `json:"forfeit_reason,omitempty"`
+	LastDrawOfferTurn   int       `json:"last_draw_offer_turn,omitempty"`
+
+	// AbilityUsage is keyed by ability ID and reset when the character's turn starts
+	AbilityUsage map[string]*AbilityUsage `json:"ability_usage,omitempty"`
+
+	// Effects are the status effects currently active on the character
+	Effects []StatusEffect `json:"effects,omitempty"`
+}
+
+// Position returns the tile the character stands on
+func (c *Character) Position() Position {
+	return Position{X: c.X, Y: c.Y}
+}
+
+// IsAlive reports whether the character is still in the fight
+func (c *Character) IsAlive() bool {
+	return c.HP > 0 && !c.Forfeited
+}
+
+// IsAlly reports whether another character fights on the same team
+func (c *Character) IsAlly(other *Character) bool {
+	return c.Team != 0 && c.Team == other.Team
+}
+
+// MatchState is the authoritative state of a running match
+type MatchState struct {
+	MatchID    uuid.UUID                `json:"match_id"`
+	Status     MatchStatus              `json:"status"`
+	Rules      Rules                    `json:"rules"`
+	Board      *Board                   `json:"board"`
+	Abilities  map[string]Ability       `json:"-"`
+	Characters map[uuid.UUID]*Character `json:"characters"`
+	TurnOrder  []uuid.UUID              `json:"turn_order"`
+	TurnNo     int                      `json:"turn_no"`
+	Result     *MatchResult             `json:"result,omitempty"`
+
+	// Seed drives the combat rolls, so it is kept from players until the match
+	// is replayed or exported
+	Seed int64 `json:"-"`
+
+	// CatalogVersion identifies the ability catalog the match is played with
+	CatalogVersion string `json:"catalog_version,omitempty"`
+
+	// Private matches have a host who may close them to spectators
+	Private            bool       `json:"private"`
+	HostUserID         *uuid.UUID `json:"host_user_id,omitempty"`
+	SpectatingDisabled bool       `json:"spectating_disabled"`
+
+	DrawOffer  *DrawOffer `json:"draw_offer,omitempty"`
+	DrawAgreed bool       `json:"draw_agreed,omitempty"`
+
+	// Conditions override DefaultConditions when set
+	Conditions []EndCondition `json:"-"`
+
+	// pendingForfeit is the reason of a forfeit made by the action being
+	// resolved; checkEnd clears it once the end conditions have seen it
+	pendingForfeit EndReason
+
+	// pcg is the random stream of the current turn, created on first use
+	pcg *rand.PCG
+}
+
+// NewMatchState builds the initial state of a match from its participants
+func NewMatchState(matchID uuid.UUID, board *Board, abilities map[string]Ability, participants []Participant) (*MatchState, error) {
+	if len(participants) < 2 {
+		return nil, ErrNotEnoughPlayers
+	}
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+
+	state := &MatchState{
+		MatchID:    matchID,
+		Status:     StatusActive,
+		Board:      board,
+		Abilities:  abilities,
+		Characters: make(map[uuid.UUID]*Character, len(participants)),
+		TurnOrder:  make([]uuid.UUID, 0, len(participants)),
+		TurnNo:     1,
+	}
+
+	for _, p := range participants {
+		pos := Position{X: p.StartX, Y: p.StartY}
+		if !board.IsWalkable(pos) {
+			return nil, fmt.Errorf("participant %s starts on a blocked tile (%d,%d)", p.UserID, p.StartX, p.StartY)
+		}
+		if state.CharacterAt(pos) != nil {
+			return nil, fmt.Errorf("participant %s starts on an occupied tile (%d,%d)", p.UserID, p.StartX, p.StartY)
+		}
+
+		state.Characters[p.UserID] = &Character{
+			UserID:     p.UserID,
+			IsBot:      p.IsBot,
+			HP:         p.StartingHP,
+			AP:         p.StartingAP,
+			X:          p.StartX,
+			Y:          p.StartY,
+			StartingHP: p.StartingHP,
+			StartingAP: p.StartingAP,
+			Team:       p.Team,
+		}
+		state.TurnOrder = append(state.TurnOrder, p.UserID)
+	}
+
+	return state, nil
+}
+
+// ActiveUserID returns the user whose turn it is
+func (s *MatchState) ActiveUserID() uuid.UUID {
+	return s.TurnOrder[(s.TurnNo-1)%len(s.TurnOrder)]
+}
+
+// ActiveCharacter returns the character whose turn it is
+func (s *MatchState) ActiveCharacter() *Character {
+	return s.Characters[s.ActiveUserID()]
+}
+
+// Alive returns the characters still in the fight, in turn order
+func (s *MatchState) Alive() []*Character {
+	var alive []*Character
+	for _, id := range s.TurnOrder {
+		if c := s.Characters[id]; c.IsAlive() {
+			alive = append(alive, c)
+		}
+	}
+	return alive
+}
+
+// Clone returns a copy of the state that can be read without holding the match lock
+func (s *MatchState) Clone() *MatchState {
+	clone := *s
+	clone.Characters = make(map[uuid.UUID]*Character, len(s.Characters))
+	for id, c := range s.Characters {
+		cc := *c
+		cc.AbilityUsage = make(map[string]*AbilityUsage, len(c.AbilityUsage))
+		for abilityID, u := range c.AbilityUsage {
+			uc := AbilityUsage{UsesThisTurn: u.UsesThisTurn, PerTargetUses: make(map[string]int, len(u.PerTargetUses))}
+			for target, n := range u.PerTargetUses {
+				uc.PerTargetUses[target] = n
+			}
+			cc.AbilityUsage[abilityID] = &uc
+		}
+		cc.Effects = append([]StatusEffect(nil), c.Effects...)
+		clone.Characters[id] = &cc
+	}
+	clone.TurnOrder = append([]uuid.UUID(nil), s.TurnOrder...)
+	if s.pcg != nil {
+		pcg := *s.pcg
+		clone.pcg = &pcg
+	}
+	if s.Result != nil {
+		result := *s.Result
+		clone.Result = &result
+	}
+	if s.DrawOffer != nil {
+		offer := *s.DrawOffer
+		offer.AcceptedBy = append([]uuid.UUID(nil), s.DrawOffer.AcceptedBy...)
+		clone.DrawOffer = &offer
+	}
+	return &clone
+}
+
+// end closes the match with the given outcome
+func (s *MatchState) end(result *MatchResult) {
+	s.Status = StatusEnded
+	s.Result = &MatchResult{WinnerUserID: result.WinnerUserID, WinnerTeam: result.WinnerTeam, Reason: result.Reason, TurnNo: s.TurnNo}
+}
+
+// CharacterAt returns the living character standing on the given tile, if any
+func (s *MatchState) CharacterAt(p Position) *Character {
+	for _, c := range s.Characters {
+		if c.IsAlive() && c.X == p.X && c.Y == p.Y {
+			return c
+		}
+	}
+	return nil
+}
+
+// ActionType identifies what a player is trying to do
+type ActionType string
+
+const (
+	ActionMove    ActionType = "move"
+	ActionCast    ActionType = "cast"
+	ActionEndTurn ActionType = "end_turn"
+
+	// Match level actions may be taken outside of the player's turn
+	ActionSurrender   ActionType = "surrender"
+	ActionOfferDraw   ActionType = "offer_draw"
+	ActionAcceptDraw  ActionType = "accept_draw"
+	ActionDeclineDraw ActionType = "decline_draw"
+
+	// System actions are issued by the server, never by clients
+	ActionTimeout ActionType = "turn.timeout"
+	ActionAbandon ActionType = "player.abandoned"
+)
+
+// IsSystem reports whether the action can only be issued by the server
+func (t ActionType) IsSystem() bool {
+	return t == ActionTimeout || t == ActionAbandon
+}
+
+// IsTurnBound reports whether the action may only be taken by the active player
+func (t ActionType) IsTurnBound() bool {
+	switch t {
+	case ActionSurrender, ActionOfferDraw, ActionAcceptDraw, ActionDeclineDraw, ActionAbandon:
+		return false
+	default:
+		return true
+	}
+}
+
+// Action is a player intent submitted to the engine
+type Action struct {
+	Type      ActionType `json:"type"`
+	UserID    uuid.UUID  `json:"user_id"`
+	AbilityID string     `json:"ability_id,omitempty"`
+	Target    Position   `json:"target"`
+	Path      []Position `json:"path,omitempty"`
+
+	// ClientActionID is chosen by the client so a retried action is applied once
+	ClientActionID string `json:"client_action_id,omitempty"`
+}
+
+// Hit records damage dealt to a single character
+type Hit struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Damage   int       `json:"damage"`
+	HPLeft   int       `json:"hp_left"`
+	Killed   bool      `json:"killed"`
+	Absorbed int       `json:"absorbed,omitempty"`
+	Missed   bool      `json:"missed,omitempty"`
+	Critical bool      `json:"critical,omitempty"`
+}
+
+// ActionResult describes the outcome of a resolved action
+type ActionResult struct {
+	Action  Action     `json:"action"`
+	TurnNo  int        `json:"turn_no"`
+	APSpent int        `json:"ap_spent"`
+	Path    []Position `json:"path,omitempty"`
+	Hits    []Hit      `json:"hits,omitempty"`
+
+	// Ticks lists the status effects resolved when this action ended the turn
+	Ticks []EffectTick `json:"ticks,omitempty"`
+}
+
+// RuleError is a rejected action, carrying a stable code for clients
+type RuleError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+func newRuleError(code, message string) *RuleError {
+	return &RuleError{Code: code, Message: message}
+}
+
+// Setup errors
+var (
+	ErrNotEnoughPlayers = errors.New("a match needs at least two participants")
+	ErrInvalidMoveCost  = errors.New("terrain move costs must be at least 1")
+)
+
+// Rule violations
+var (
+	ErrMatchNotActive     = newRuleError("match_not_active", "match is not active")
+	ErrNotParticipant     = newRuleError("not_participant", "user is not part of this match")
+	ErrCharacterDead      = newRuleError("character_dead", "character is dead")
+	ErrNotYourTurn        = newRuleError("not_your_turn", "it is not your turn")
+	ErrUnknownAction      = newRuleError("unknown_action", "unknown action type")
+	ErrUnknownAbility     = newRuleError("unknown_ability", "unknown ability")
+	ErrOutOfBounds        = newRuleError("out_of_bounds", "target is outside the board")
+	ErrTileBlocked        = newRuleError("tile_blocked", "target tile is blocked")
+	ErrTileOccupied       = newRuleError("tile_occupied", "target tile is occupied")
+	ErrInvalidMove        = newRuleError("invalid_move", "destination must differ from current position")
+	ErrNoPath             = newRuleError("no_path", "destination cannot be reached")
+	ErrInvalidPath        = newRuleError("invalid_path", "path is not a legal route to the destination")
+	ErrPathNotCheapest    = newRuleError("path_not_cheapest", "path costs more than the cheapest route")
+	ErrInsufficientAP     = newRuleError("insufficient_ap", "not enough action points")
+	ErrOutOfRange         = newRuleError("out_of_range", "target is out of range")
+	ErrNoLineOfSight      = newRuleError("no_line_of_sight", "target is not in line of sight")
+	ErrNoTarget           = newRuleError("no_target", "no character on target tile")
+	ErrTurnLimitReached   = newRuleError("ability_turn_limit", "ability has reached its per turn limit")
+	ErrTargetLimitReached = newRuleError("ability_target_limit", "ability has reached its per target per turn limit")
+	ErrDrawOfferPending   = newRuleError("draw_offer_pending", "a draw offer is already pending")
+	ErrDrawOfferCooldown  = newRuleError("draw_offer_cooldown", "draw offered too recently")
+	ErrNoDrawOffer        = newRuleError("no_draw_offer", "there is no pending draw offer")
+	ErrOwnDrawOffer       = newRuleError("own_draw_offer", "cannot answer your own draw offer")
+	ErrSystemAction       = newRuleError("system_action", "action can only be issued by the server")
+	ErrSpectatingDisabled = newRuleError("spectating_disabled", "the host disabled spectating for this match")
+	ErrAlreadyPlaying     = newRuleError("already_playing", "players cannot spectate their own match")
+	ErrNotPrivate         = newRuleError("match_not_private", "only private matches can close spectating")
+	ErrNotHost            = newRuleError("not_host", "only the host can change this setting")
+	ErrInvalidActionID    = newRuleError("invalid_client_action_id", "client action id must be at most 64 characters")
+)
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
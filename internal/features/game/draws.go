@@ -0,0 +1,88 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// DrawOffer is a pending proposal to end the match in a draw. It lapses when
+// the offering player's next turn starts.
+type DrawOffer struct {
+	FromUserID uuid.UUID   `json:"from_user_id"`
+	TurnNo     int         `json:"turn_no"`
+	AcceptedBy []uuid.UUID `json:"accepted_by"`
+}
+
+// applySurrender forfeits the actor, passing the turn on if it was theirs
+func applySurrender(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	result := &ActionResult{Action: action, TurnNo: state.TurnNo}
+	wasActive := state.ActiveUserID() == actor.UserID
+
+	state.forfeitCharacter(actor, EndReasonSurrender)
+	if state.checkEnd() {
+		return result, nil
+	}
+
+	if wasActive {
+		result.Ticks = advanceTurn(state)
+	}
+	return result, nil
+}
+
+// applyOfferDraw proposes a draw, at most once every DrawOfferCooldownTurns turns per player
+func applyOfferDraw(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	if state.DrawOffer != nil {
+		return nil, ErrDrawOfferPending
+	}
+
+	cooldown := state.Rules.DrawOfferCooldownTurns
+	if actor.LastDrawOfferTurn > 0 && state.TurnNo-actor.LastDrawOfferTurn < cooldown {
+		return nil, ErrDrawOfferCooldown
+	}
+
+	actor.LastDrawOfferTurn = state.TurnNo
+	state.DrawOffer = &DrawOffer{FromUserID: actor.UserID, TurnNo: state.TurnNo}
+
+	return &ActionResult{Action: action, TurnNo: state.TurnNo}, nil
+}
+
+// applyAcceptDraw records an acceptance; the draw is agreed once every other
+// player still in the fight has accepted
+func applyAcceptDraw(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	offer := state.DrawOffer
+	if offer == nil {
+		return nil, ErrNoDrawOffer
+	}
+	if offer.FromUserID == actor.UserID {
+		return nil, ErrOwnDrawOffer
+	}
+
+	if !slices.Contains(offer.AcceptedBy, actor.UserID) {
+		offer.AcceptedBy = append(offer.AcceptedBy, actor.UserID)
+	}
+
+	agreed := true
+	for _, c := range state.Alive() {
+		if c.UserID != offer.FromUserID && !slices.Contains(offer.AcceptedBy, c.UserID) {
+			agreed = false
+			break
+		}
+	}
+	state.DrawAgreed = agreed
+
+	return &ActionResult{Action: action, TurnNo: state.TurnNo}, nil
+}
+
+// applyDeclineDraw withdraws the pending offer
+func applyDeclineDraw(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	if state.DrawOffer == nil {
+		return nil, ErrNoDrawOffer
+	}
+	if state.DrawOffer.FromUserID == actor.UserID {
+		return nil, ErrOwnDrawOffer
+	}
+
+	state.DrawOffer = nil
+	return &ActionResult{Action: action, TurnNo: state.TurnNo}, nil
+}
@@ -0,0 +1,151 @@
+package game
+
+import "github.com/google/uuid"
+
+// EffectKind names a timed status effect
+type EffectKind string
+
+const (
+	// EffectBurn deals potency damage per stack when its owner's turn starts
+	EffectBurn EffectKind = "burn"
+	// EffectStun drains all AP when its owner's turn starts
+	EffectStun EffectKind = "stun"
+	// EffectShield absorbs up to potency damage from hits
+	EffectShield EffectKind = "shield"
+	// EffectSlow removes potency AP when its owner's turn starts
+	EffectSlow EffectKind = "slow"
+)
+
+// tickPhase is the moment of the owner's turn at which an effect resolves
+type tickPhase int
+
+const (
+	tickTurnStart tickPhase = iota
+	tickTurnEnd
+)
+
+// effectRule describes how an effect kind ticks and stacks
+type effectRule struct {
+	tick      tickPhase
+	maxStacks int
+}
+
+var effectRules = map[EffectKind]effectRule{
+	EffectBurn:   {tick: tickTurnStart, maxStacks: 3},
+	EffectStun:   {tick: tickTurnStart, maxStacks: 1},
+	EffectShield: {tick: tickTurnEnd, maxStacks: 1},
+	EffectSlow:   {tick: tickTurnStart, maxStacks: 1},
+}
+
+// EffectSpec is an effect an ability applies on hit, or to the caster when OnSelf is set
+type EffectSpec struct {
+	Kind    EffectKind `json:"kind"`
+	Turns   int        `json:"turns"`
+	Potency int        `json:"potency"`
+	OnSelf  bool       `json:"on_self,omitempty"`
+}
+
+// StatusEffect is an effect currently active on a character. Turns counts the
+// owner's remaining turns.
+type StatusEffect struct {
+	Kind         EffectKind `json:"kind"`
+	Turns        int        `json:"turns"`
+	Stacks       int        `json:"stacks"`
+	Potency      int        `json:"potency"`
+	SourceUserID uuid.UUID  `json:"source_user_id"`
+}
+
+// EffectTick reports the resolution of an effect at a turn boundary
+type EffectTick struct {
+	UserID  uuid.UUID  `json:"user_id"`
+	Kind    EffectKind `json:"kind"`
+	Damage  int        `json:"damage,omitempty"`
+	APLost  int        `json:"ap_lost,omitempty"`
+	HPLeft  int        `json:"hp_left"`
+	Expired bool       `json:"expired,omitempty"`
+}
+
+// applyEffect adds an effect to a character. Stackable effects gain a stack up
+// to their cap, every effect refreshes to the longer duration and the
+// stronger potency.
+func applyEffect(target *Character, spec EffectSpec, source uuid.UUID) {
+	rule, ok := effectRules[spec.Kind]
+	if !ok || spec.Turns <= 0 {
+		return
+	}
+
+	for i := range target.Effects {
+		e := &target.Effects[i]
+		if e.Kind != spec.Kind {
+			continue
+		}
+		if e.Stacks < rule.maxStacks {
+			e.Stacks++
+		}
+		e.Turns = max(e.Turns, spec.Turns)
+		e.Potency = max(e.Potency, spec.Potency)
+		e.SourceUserID = source
+		return
+	}
+
+	target.Effects = append(target.Effects, StatusEffect{
+		Kind:         spec.Kind,
+		Turns:        spec.Turns,
+		Stacks:       1,
+		Potency:      spec.Potency,
+		SourceUserID: source,
+	})
+}
+
+// tickEffects resolves the character's effects for a turn phase, counting
+// their durations down and dropping expired ones
+func tickEffects(c *Character, phase tickPhase) []EffectTick {
+	var ticks []EffectTick
+	kept := c.Effects[:0]
+
+	for _, e := range c.Effects {
+		if effectRules[e.Kind].tick != phase {
+			kept = append(kept, e)
+			continue
+		}
+
+		tick := EffectTick{UserID: c.UserID, Kind: e.Kind}
+		switch e.Kind {
+		case EffectBurn:
+			tick.Damage = min(e.Potency*e.Stacks, c.HP)
+			c.HP -= tick.Damage
+		case EffectStun:
+			tick.APLost = c.AP
+			c.AP = 0
+		case EffectSlow:
+			tick.APLost = min(e.Potency, c.AP)
+			c.AP -= tick.APLost
+		}
+		tick.HPLeft = c.HP
+
+		e.Turns--
+		if e.Turns > 0 {
+			kept = append(kept, e)
+		} else {
+			tick.Expired = true
+		}
+		ticks = append(ticks, tick)
+	}
+
+	c.Effects = kept
+	return ticks
+}
+
+// absorbDamage lets an active shield soak up damage, returning what gets through
+func absorbDamage(c *Character, damage int) (taken, absorbed int) {
+	for i := range c.Effects {
+		e := &c.Effects[i]
+		if e.Kind != EffectShield || e.Potency <= 0 {
+			continue
+		}
+		absorbed = min(e.Potency, damage)
+		e.Potency -= absorbed
+		damage -= absorbed
+	}
+	return damage, absorbed
+}
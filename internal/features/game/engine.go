@@ -0,0 +1,256 @@
+package game
+
+// ApplyAction validates an action against the current state and resolves it.
+// The state is only mutated when the action is legal.
+func ApplyAction(state *MatchState, action Action) (*ActionResult, error) {
+	if state.Status != StatusActive {
+		return nil, ErrMatchNotActive
+	}
+
+	actor, ok := state.Characters[action.UserID]
+	if !ok {
+		return nil, ErrNotParticipant
+	}
+	if !actor.IsAlive() {
+		return nil, ErrCharacterDead
+	}
+	if action.Type.IsTurnBound() && state.ActiveUserID() != action.UserID {
+		return nil, ErrNotYourTurn
+	}
+
+	var (
+		result *ActionResult
+		err    error
+	)
+	switch action.Type {
+	case ActionMove:
+		result, err = applyMove(state, actor, action)
+	case ActionCast:
+		result, err = applyCast(state, actor, action)
+	case ActionEndTurn:
+		result, err = applyEndTurn(state, actor, action)
+	case ActionSurrender:
+		result, err = applySurrender(state, actor, action)
+	case ActionOfferDraw:
+		result, err = applyOfferDraw(state, actor, action)
+	case ActionAcceptDraw:
+		result, err = applyAcceptDraw(state, actor, action)
+	case ActionDeclineDraw:
+		result, err = applyDeclineDraw(state, actor, action)
+	case ActionTimeout:
+		return applyTimeout(state, actor, action)
+	case ActionAbandon:
+		return applyAbandon(state, actor, action)
+	default:
+		return nil, ErrUnknownAction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state.checkEnd()
+
+	// Any action taken by the player proves they are still at the keyboard
+	actor.ConsecutiveTimeouts = 0
+	return result, nil
+}
+
+// applyEndTurn passes the turn to the next player
+func applyEndTurn(state *MatchState, _ *Character, action Action) (*ActionResult, error) {
+	result := &ActionResult{Action: action, TurnNo: state.TurnNo}
+	result.Ticks = advanceTurn(state)
+	return result, nil
+}
+
+// applyTimeout ends the turn of a player who ran out of time, forfeiting them
+// once they reach the consecutive timeout limit
+func applyTimeout(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	result := &ActionResult{Action: action, TurnNo: state.TurnNo}
+
+	actor.ConsecutiveTimeouts++
+	limit := state.Rules.MaxConsecutiveTimeouts
+	if limit > 0 && actor.ConsecutiveTimeouts >= limit {
+		state.forfeitCharacter(actor, EndReasonTimeoutForfeit)
+		if state.checkEnd() {
+			return result, nil
+		}
+	}
+
+	result.Ticks = advanceTurn(state)
+	state.checkEnd()
+	return result, nil
+}
+
+// applyAbandon forfeits a player whose disconnect grace period ran out
+func applyAbandon(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	result := &ActionResult{Action: action, TurnNo: state.TurnNo}
+	wasActive := state.ActiveUserID() == actor.UserID
+
+	state.forfeitCharacter(actor, EndReasonAbandoned)
+	if state.checkEnd() {
+		return result, nil
+	}
+
+	if wasActive {
+		result.Ticks = advanceTurn(state)
+		state.checkEnd()
+	}
+	return result, nil
+}
+
+// advanceTurn resolves end of turn effects, then hands the turn to the next
+// character still in the fight, refilling its AP from starting_ap and
+// resolving its start of turn effects
+func advanceTurn(state *MatchState) []EffectTick {
+	ticks := tickEffects(state.ActiveCharacter(), tickTurnEnd)
+
+	for range state.TurnOrder {
+		state.TurnNo++
+
+		// Each turn draws from its own stream
+		state.pcg = nil
+
+		active := state.ActiveCharacter()
+		if !active.IsAlive() {
+			continue
+		}
+
+		active.AP = active.StartingAP
+		active.AbilityUsage = nil
+
+		// An unanswered draw offer lapses on the offering player's next turn
+		if state.DrawOffer != nil && state.DrawOffer.FromUserID == active.UserID {
+			state.DrawOffer = nil
+		}
+		ticks = append(ticks, tickEffects(active, tickTurnStart)...)
+
+		// A burn may finish the character before it gets to act
+		if active.IsAlive() {
+			break
+		}
+	}
+
+	return ticks
+}
+
+// applyMove walks the actor to the target tile along the cheapest legal path,
+// paying the terrain cost of every step. A client supplied path is only
+// accepted if it is legal and costs no more than the cheapest one; it may
+// pick another route of the same cost.
+func applyMove(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	dest := action.Target
+	if !state.Board.InBounds(dest) {
+		return nil, ErrOutOfBounds
+	}
+	if !state.Board.IsWalkable(dest) {
+		return nil, ErrTileBlocked
+	}
+	if dest == actor.Position() {
+		return nil, ErrInvalidMove
+	}
+	if state.CharacterAt(dest) != nil {
+		return nil, ErrTileOccupied
+	}
+
+	occupied := func(p Position) bool {
+		c := state.CharacterAt(p)
+		return c != nil && c != actor
+	}
+
+	path, cost, ok := FindPath(state.Board, actor.Position(), dest, occupied)
+	if !ok {
+		return nil, ErrNoPath
+	}
+	if len(action.Path) > 0 {
+		pathCost, ok := PathCost(state.Board, actor.Position(), action.Path, occupied)
+		if !ok || action.Path[len(action.Path)-1] != dest {
+			return nil, ErrInvalidPath
+		}
+		if pathCost != cost {
+			return nil, ErrPathNotCheapest
+		}
+		path = action.Path
+	}
+
+	if cost > actor.AP {
+		return nil, ErrInsufficientAP
+	}
+
+	actor.AP -= cost
+	actor.X, actor.Y = dest.X, dest.Y
+
+	return &ActionResult{Action: action, TurnNo: state.TurnNo, APSpent: cost, Path: path}, nil
+}
+
+// applyCast resolves an ability cast on the target tile
+func applyCast(state *MatchState, actor *Character, action Action) (*ActionResult, error) {
+	ability, ok := state.Abilities[action.AbilityID]
+	if !ok {
+		return nil, ErrUnknownAbility
+	}
+	if err := checkTarget(state, actor, ability, actor.Position(), action.Target); err != nil {
+		return nil, err
+	}
+	if ability.APCost > actor.AP {
+		return nil, ErrInsufficientAP
+	}
+
+	targets := castTargets(state, actor, ability, action.Target)
+	if err := checkUsage(actor, ability, targets); err != nil {
+		return nil, err
+	}
+
+	actor.AP -= ability.APCost
+	recordUsage(actor, ability, targets)
+
+	result := &ActionResult{Action: action, TurnNo: state.TurnNo, APSpent: ability.APCost}
+	rng := state.random()
+	for _, target := range targets {
+		damage, missed, critical := rollDamage(rng, ability.BaseDamage)
+		hit := dealDamage(target, damage)
+		hit.Missed, hit.Critical = missed, critical
+		result.Hits = append(result.Hits, hit)
+
+		if !missed && target.IsAlive() {
+			for _, spec := range ability.Effects {
+				if !spec.OnSelf {
+					applyEffect(target, spec, actor.UserID)
+				}
+			}
+		}
+	}
+	for _, spec := range ability.Effects {
+		if spec.OnSelf {
+			applyEffect(actor, spec, actor.UserID)
+		}
+	}
+
+	return result, nil
+}
+
+// castTargets returns the living characters affected by a cast, excluding the caster
+func castTargets(state *MatchState, caster *Character, ability Ability, target Position) []*Character {
+	var hit []*Character
+	for _, p := range AffectedTiles(state.Board, ability, caster.Position(), target) {
+		if c := state.CharacterAt(p); c != nil && c != caster {
+			hit = append(hit, c)
+		}
+	}
+	return hit
+}
+
+// dealDamage lowers the target's HP after shields, without going below zero
+func dealDamage(target *Character, damage int) Hit {
+	taken, absorbed := absorbDamage(target, damage)
+	target.HP -= taken
+	if target.HP < 0 {
+		target.HP = 0
+	}
+	return Hit{
+		UserID:   target.UserID,
+		Damage:   taken,
+		HPLeft:   target.HP,
+		Killed:   target.HP == 0,
+		Absorbed: absorbed,
+	}
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// arena seats a player on from and an opponent in the bottom right corner of
+// the board; the player moves first
+func arena(t *testing.T, board *Board, from Position, ap int) (*MatchState, uuid.UUID) {
+	t.Helper()
+	a, b := uuid.New(), uuid.New()
+	abilities := map[string]Ability{
+		"bolt": {ID: "bolt", BaseDamage: 10, APCost: 2, Range: 4},
+	}
+	state, err := NewMatchState(uuid.New(), board, abilities, []Participant{
+		{UserID: a, StartingHP: 100, StartingAP: ap, StartX: from.X, StartY: from.Y},
+		{UserID: b, StartingHP: 100, StartingAP: ap, StartX: board.Width - 1, StartY: board.Height - 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state, a
+}
+
+func TestApplyMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    *Board
+		from     Position
+		ap       int
+		target   Position
+		path     []Position
+		wantErr  error
+		wantPath []Position
+		wantAP   int
+	}{
+		{
+			name:     "server picks the cheapest path",
+			board:    NewBoard(4, 4),
+			from:     Position{X: 0, Y: 0},
+			ap:       6,
+			target:   Position{X: 2, Y: 0},
+			wantPath: []Position{{X: 1, Y: 0}, {X: 2, Y: 0}},
+			wantAP:   4,
+		},
+		{
+			name: "rough terrain costs more",
+			board: testBoard(
+				".~..",
+				"#...",
+				"....",
+				"....",
+			),
+			from:     Position{X: 0, Y: 0},
+			ap:       6,
+			target:   Position{X: 1, Y: 0},
+			wantPath: []Position{{X: 1, Y: 0}},
+			wantAP:   4,
+		},
+		{
+			name:     "client picks another route of the same cost",
+			board:    NewBoard(4, 4),
+			from:     Position{X: 0, Y: 0},
+			ap:       6,
+			target:   Position{X: 1, Y: 1},
+			path:     []Position{{X: 0, Y: 1}, {X: 1, Y: 1}},
+			wantPath: []Position{{X: 0, Y: 1}, {X: 1, Y: 1}},
+			wantAP:   4,
+		},
+		{
+			name:    "client detour is refused",
+			board:   NewBoard(4, 4),
+			from:    Position{X: 0, Y: 0},
+			ap:      6,
+			target:  Position{X: 1, Y: 0},
+			path:    []Position{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}},
+			wantErr: ErrPathNotCheapest,
+		},
+		{
+			name: "client path through rough terrain is refused when floor is as short",
+			board: testBoard(
+				".~..",
+				"....",
+				"....",
+				"....",
+			),
+			from:    Position{X: 0, Y: 0},
+			ap:      6,
+			target:  Position{X: 1, Y: 1},
+			path:    []Position{{X: 1, Y: 0}, {X: 1, Y: 1}},
+			wantErr: ErrPathNotCheapest,
+		},
+		{
+			name:    "client path with a jump",
+			board:   NewBoard(4, 4),
+			from:    Position{X: 0, Y: 0},
+			ap:      6,
+			target:  Position{X: 2, Y: 0},
+			path:    []Position{{X: 2, Y: 0}},
+			wantErr: ErrInvalidPath,
+		},
+		{
+			name:    "client path ending elsewhere",
+			board:   NewBoard(4, 4),
+			from:    Position{X: 0, Y: 0},
+			ap:      6,
+			target:  Position{X: 2, Y: 0},
+			path:    []Position{{X: 1, Y: 0}},
+			wantErr: ErrInvalidPath,
+		},
+		{
+			name:    "not enough AP",
+			board:   NewBoard(4, 4),
+			from:    Position{X: 0, Y: 0},
+			ap:      2,
+			target:  Position{X: 3, Y: 0},
+			wantErr: ErrInsufficientAP,
+		},
+		{
+			name: "walled off",
+			board: testBoard(
+				".#..",
+				"#...",
+				"....",
+				"....",
+			),
+			from:    Position{X: 0, Y: 0},
+			ap:      6,
+			target:  Position{X: 2, Y: 0},
+			wantErr: ErrNoPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, a := arena(t, tt.board, tt.from, tt.ap)
+			result, err := ApplyAction(state, Action{Type: ActionMove, UserID: a, Target: tt.target, Path: tt.path})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			actor := state.Characters[a]
+			if tt.wantErr != nil {
+				if actor.Position() != tt.from || actor.AP != tt.ap {
+					t.Errorf("refused move changed the actor: at %v with %d AP", actor.Position(), actor.AP)
+				}
+				return
+			}
+			if !reflect.DeepEqual(result.Path, tt.wantPath) {
+				t.Errorf("path = %v, want %v", result.Path, tt.wantPath)
+			}
+			if actor.Position() != tt.target {
+				t.Errorf("actor at %v, want %v", actor.Position(), tt.target)
+			}
+			if actor.AP != tt.wantAP || result.APSpent != tt.ap-tt.wantAP {
+				t.Errorf("AP = %d (spent %d), want %d", actor.AP, result.APSpent, tt.wantAP)
+			}
+		})
+	}
+}
+
+func TestBoardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		costs   map[Terrain]int
+		wantErr bool
+	}{
+		{name: "defaults", costs: nil},
+		{name: "costlier rough", costs: map[Terrain]int{TerrainRough: 3}},
+		{name: "free floor", costs: map[Terrain]int{TerrainFloor: 0}, wantErr: true},
+		{name: "negative rough", costs: map[Terrain]int{TerrainRough: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard(4, 4)
+			board.MoveCosts = tt.costs
+			_, err := NewMatchState(uuid.New(), board, nil, []Participant{
+				{UserID: uuid.New(), StartX: 0, StartY: 0},
+				{UserID: uuid.New(), StartX: 3, StartY: 3},
+			})
+			if got := errors.Is(err, ErrInvalidMoveCost); got != tt.wantErr {
+				t.Errorf("err = %v, want invalid move cost: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
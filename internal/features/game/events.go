@@ -0,0 +1,119 @@
+package game
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbound message types sent to match participants and spectators
+const (
+	EventTurnStarted    = "turn.started"
+	EventTurnTimeout    = "turn.timeout"
+	EventActionApplied  = "action.applied"
+	EventMatchEnded     = "match.ended"
+	EventSpectators     = "match.spectators"
+	EventSpectateClosed = "spectate.closed"
+	EventDisconnected   = "player.disconnected"
+	EventReconnected    = "player.reconnected"
+)
+
+// Event is a message pushed to connected users. Seq is the action log seq of
+// the last action applied before a player event, so a reconnecting client can
+// ask for what it missed even across a server restart. Events that follow the
+// same action share it; it is zero before the first action and for events
+// outside of the match sequence.
+type Event struct {
+	Seq  int         `json:"seq,omitempty"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Audience picks one of the two rooms of a match
+type Audience int
+
+const (
+	// AudiencePlayers are the participants of a match, forfeited ones included
+	AudiencePlayers Audience = iota
+	// AudienceSpectators see the events of a match once they are delayed
+	AudienceSpectators
+)
+
+// Notifier delivers match events to the rooms of a match. Users join a room
+// once and receive its events on every connection they have open, until they
+// leave or the match closes.
+type Notifier interface {
+	JoinMatch(matchID uuid.UUID, audience Audience, userIDs ...uuid.UUID)
+	LeaveMatch(matchID uuid.UUID, audience Audience, userIDs ...uuid.UUID)
+	NotifyMatch(matchID uuid.UUID, audience Audience, event Event)
+	CloseMatch(matchID uuid.UUID)
+}
+
+// noopNotifier drops every event until a transport is attached
+type noopNotifier struct{}
+
+func (noopNotifier) JoinMatch(uuid.UUID, Audience, ...uuid.UUID)  {}
+func (noopNotifier) LeaveMatch(uuid.UUID, Audience, ...uuid.UUID) {}
+func (noopNotifier) NotifyMatch(uuid.UUID, Audience, Event)       {}
+func (noopNotifier) CloseMatch(uuid.UUID)                         {}
+
+// EndListener is told about every match that ended, once its result is stored
+type EndListener interface {
+	MatchEnded(state *MatchState)
+}
+
+// TurnStartedEvent announces a new turn and its deadline. A turn starting
+// while its player is away under the pause policy has no deadline yet.
+type TurnStartedEvent struct {
+	MatchID      uuid.UUID `json:"match_id"`
+	TurnNo       int       `json:"turn_no"`
+	ActiveUserID uuid.UUID `json:"active_user_id"`
+	AP           int       `json:"ap"`
+	Deadline     time.Time `json:"deadline"`
+	Paused       bool      `json:"paused,omitempty"`
+}
+
+// TurnTimeoutEvent announces that a player ran out of time
+type TurnTimeoutEvent struct {
+	MatchID             uuid.UUID `json:"match_id"`
+	UserID              uuid.UUID `json:"user_id"`
+	TurnNo              int       `json:"turn_no"`
+	ConsecutiveTimeouts int       `json:"consecutive_timeouts"`
+	Forfeited           bool      `json:"forfeited"`
+}
+
+// ActionAppliedEvent shares a resolved action with every participant
+type ActionAppliedEvent struct {
+	MatchID uuid.UUID     `json:"match_id"`
+	Result  *ActionResult `json:"result"`
+}
+
+// MatchEndedEvent is the final message of a match with its result summary
+type MatchEndedEvent struct {
+	MatchID uuid.UUID       `json:"match_id"`
+	Result  *MatchResult    `json:"result"`
+	Players []PlayerSummary `json:"players"`
+	EndedAt time.Time       `json:"ended_at"`
+}
+
+// SpectatorsEvent tells players how many users are watching their match
+type SpectatorsEvent struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Count   int       `json:"count"`
+}
+
+// SpectateClosedEvent tells spectators they were removed from a match
+type SpectateClosedEvent struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Reason  string    `json:"reason"`
+}
+
+// PresenceEvent tells players that someone dropped or came back. While a
+// player is away GraceDeadline is when they will be considered gone.
+type PresenceEvent struct {
+	MatchID       uuid.UUID  `json:"match_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	GraceDeadline *time.Time `json:"grace_deadline,omitempty"`
+	TurnPaused    bool       `json:"turn_paused"`
+	Deadline      time.Time  `json:"deadline"`
+}
@@ -0,0 +1,316 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportVersion is the version of the match export document this build writes
+// and reads
+const ExportVersion = 1
+
+var (
+	ErrUnsupportedExport = errors.New("unsupported match export version")
+	ErrMatchExists       = errors.New("match already exists")
+	ErrResultMismatch    = errors.New("re-simulated outcome does not match the recorded one")
+	ErrCatalogMismatch   = errors.New("shipped ability catalog is not the one the match was played with")
+)
+
+// Export is a portable, self-contained record of a match: everything needed
+// to re-simulate it offline or load it into another database
+type Export struct {
+	Version      int            `json:"version"`
+	ExportedAt   time.Time      `json:"exported_at"`
+	Match        ExportedMatch  `json:"match"`
+	Participants []Participant  `json:"participants"`
+	Catalog      ExportCatalog  `json:"catalog"`
+	Actions      []*ActionEntry `json:"actions"`
+}
+
+// ExportedMatch is the metadata of an exported match
+type ExportedMatch struct {
+	ID             uuid.UUID   `json:"id"`
+	Status         MatchStatus `json:"status"`
+	StartedAt      time.Time   `json:"started_at"`
+	EndedAt        *time.Time  `json:"ended_at"`
+	WinnerUserID   *uuid.UUID  `json:"winner_user_id"`
+	WinnerTeam     *int        `json:"winner_team,omitempty"`
+	EndReason      *EndReason  `json:"end_reason"`
+	Seed           int64       `json:"seed"`
+	Rules          Rules       `json:"rules"`
+	CatalogVersion string      `json:"catalog_version"`
+
+	Private            bool       `json:"private"`
+	HostUserID         *uuid.UUID `json:"host_user_id,omitempty"`
+	SpectatingDisabled bool       `json:"spectating_disabled"`
+	Queue              *string    `json:"queue,omitempty"`
+	Ranked             bool       `json:"ranked"`
+	RatedAt            *time.Time `json:"rated_at,omitempty"`
+}
+
+// ExportCatalog is the ability catalog the match was played with, shipped so
+// the export can be re-simulated without the database
+type ExportCatalog struct {
+	Version   string    `json:"version"`
+	Abilities []Ability `json:"abilities"`
+}
+
+// Decode reads an export document and checks it can be understood
+func Decode(r io.Reader) (*Export, error) {
+	var doc Export
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode match export: %w", err)
+	}
+	if doc.Version != ExportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedExport, doc.Version)
+	}
+	return &doc, nil
+}
+
+// Encode writes the export as indented JSON
+func (e *Export) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// CatalogChanged reports whether the shipped abilities are labelled with
+// another version than the match, as in hand-edited files, which can make a
+// re-simulation diverge
+func (e *Export) CatalogChanged() bool {
+	return e.Match.CatalogVersion != "" && e.Match.CatalogVersion != e.Catalog.Version
+}
+
+// Simulate replays the exported actions from the starting setup without a
+// database. onTurn is passed to Fold.
+func (e *Export) Simulate(onTurn func(*MatchState)) (*MatchState, []*ActionResult, error) {
+	state, err := SetupMatch(e.Match.ID, e.Match.Rules, e.abilities(), e.Participants)
+	if err != nil {
+		return nil, nil, err
+	}
+	state.Seed = e.Match.Seed
+	state.CatalogVersion = e.Match.CatalogVersion
+
+	results, err := Fold(state, e.Actions, onTurn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, results, nil
+}
+
+// abilities returns the shipped catalog keyed by ability ID
+func (e *Export) abilities() map[string]Ability {
+	abilities := make(map[string]Ability, len(e.Catalog.Abilities))
+	for _, a := range e.Catalog.Abilities {
+		abilities[a.ID] = a
+	}
+	return abilities
+}
+
+// Validate re-simulates the match and checks it ends the way it was recorded
+func (e *Export) Validate() (*MatchState, []*ActionResult, error) {
+	state, results, err := e.Simulate(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if e.Match.Status != StatusEnded {
+		if state.Status == StatusEnded {
+			return state, results, fmt.Errorf("%w: match is recorded as %s but ends after %d actions", ErrResultMismatch, e.Match.Status, len(results))
+		}
+		return state, results, nil
+	}
+
+	if state.Status != StatusEnded {
+		return state, results, fmt.Errorf("%w: match is recorded as ended but is still running on turn %d", ErrResultMismatch, state.TurnNo)
+	}
+	if e.Match.EndReason != nil && *e.Match.EndReason != state.Result.Reason {
+		return state, results, fmt.Errorf("%w: recorded end reason %s, simulated %s", ErrResultMismatch, *e.Match.EndReason, state.Result.Reason)
+	}
+	if !sameUser(e.Match.WinnerUserID, state.Result.WinnerUserID) || !sameTeam(e.Match.WinnerTeam, state.Result.WinnerTeam) {
+		return state, results, fmt.Errorf("%w: recorded and simulated winners differ", ErrResultMismatch)
+	}
+
+	return state, results, nil
+}
+
+// Export builds the portable document of a stored match
+func (s *Service) Export(ctx context.Context, matchID uuid.UUID) (*Export, error) {
+	record, err := s.repo.GetMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListActions(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match actions: %w", err)
+	}
+	abilities, err := s.catalog.SpecsAt(ctx, record.CatalogVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ability catalog %q: %w", record.CatalogVersion, err)
+	}
+
+	rules := record.Rules
+	if rules.IsZero() {
+		rules = s.rules
+	}
+
+	doc := &Export{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Match: ExportedMatch{
+			ID:             record.MatchID,
+			Status:         record.Status,
+			StartedAt:      record.StartedAt,
+			EndedAt:        record.EndedAt,
+			WinnerUserID:   record.WinnerUserID,
+			WinnerTeam:     record.WinnerTeam,
+			EndReason:      record.EndReason,
+			Seed:           record.Seed,
+			Rules:          rules,
+			CatalogVersion: record.CatalogVersion,
+
+			Private:            record.Private,
+			HostUserID:         record.HostUserID,
+			SpectatingDisabled: record.SpectatingDisabled,
+			Queue:              record.Queue,
+			Ranked:             record.Ranked,
+			RatedAt:            record.RatedAt,
+		},
+		Participants: record.Participants,
+		Catalog:      ExportCatalog{Version: record.CatalogVersion, Abilities: make([]Ability, 0, len(abilities))},
+		Actions:      entries,
+	}
+	for _, id := range sortedKeys(abilities) {
+		doc.Catalog.Abilities = append(doc.Catalog.Abilities, abilities[id])
+	}
+	if doc.Actions == nil {
+		doc.Actions = []*ActionEntry{}
+	}
+
+	return doc, nil
+}
+
+// CheckCatalog checks that an export ships the catalog version its match was
+// played with and that, if that version is already stored here, the shipped
+// abilities are the stored ones. Storing keeps the first copy of a version, so
+// a file with edited stats would validate but replay differently afterwards.
+func (s *Service) CheckCatalog(ctx context.Context, doc *Export) error {
+	if doc.Match.CatalogVersion == "" || doc.CatalogChanged() {
+		return fmt.Errorf("%w: match %q, shipped %q", ErrCatalogMismatch, doc.Match.CatalogVersion, doc.Catalog.Version)
+	}
+
+	stored, err := s.catalog.SpecsAt(ctx, doc.Catalog.Version)
+	if errors.Is(err, ErrCatalogVersionMissing) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load ability catalog %q: %w", doc.Catalog.Version, err)
+	}
+
+	same, err := sameAbilities(stored, doc.abilities())
+	if err != nil {
+		return err
+	}
+	if !same {
+		return fmt.Errorf("%w: shipped abilities differ from the stored catalog %q", ErrCatalogMismatch, doc.Catalog.Version)
+	}
+	return nil
+}
+
+// sameAbilities compares two catalogs by their JSON form, the form they are
+// stored and shipped in
+func sameAbilities(a, b map[string]Ability) (bool, error) {
+	rawA, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	rawB, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(rawA, rawB), nil
+}
+
+// Import validates an export and stores it with regenerated snapshots and
+// the catalog version it ships, so the match replays here exactly as it did.
+// Matches exported while still running are stored as ended without a result
+// so the server never tries to resume them.
+func (s *Service) Import(ctx context.Context, doc *Export) error {
+	if err := s.CheckCatalog(ctx, doc); err != nil {
+		return err
+	}
+	if _, _, err := doc.Validate(); err != nil {
+		return err
+	}
+
+	var snapshots []*MatchState
+	if _, _, err := doc.Simulate(func(state *MatchState) {
+		snapshots = append(snapshots, state.Clone())
+	}); err != nil {
+		return err
+	}
+
+	record := &MatchRecord{
+		MatchID:        doc.Match.ID,
+		Status:         StatusEnded,
+		Seed:           doc.Match.Seed,
+		Rules:          doc.Match.Rules,
+		CatalogVersion: doc.Match.CatalogVersion,
+		StartedAt:      doc.Match.StartedAt,
+		EndedAt:        doc.Match.EndedAt,
+		WinnerUserID:   doc.Match.WinnerUserID,
+		WinnerTeam:     doc.Match.WinnerTeam,
+		EndReason:      doc.Match.EndReason,
+		Participants:   doc.Participants,
+
+		Private:            doc.Match.Private,
+		HostUserID:         doc.Match.HostUserID,
+		SpectatingDisabled: doc.Match.SpectatingDisabled,
+		Queue:              doc.Match.Queue,
+		Ranked:             doc.Match.Ranked,
+		RatedAt:            doc.Match.RatedAt,
+	}
+	if record.EndedAt == nil {
+		endedAt := doc.ExportedAt
+		record.EndedAt = &endedAt
+	}
+	// Ratings were applied where the match was played; an imported match
+	// never moves ratings here
+	if record.Ranked && record.RatedAt == nil {
+		record.RatedAt = record.EndedAt
+	}
+
+	if err := s.catalog.SaveSpecs(ctx, doc.Catalog.Version, doc.abilities()); err != nil {
+		return fmt.Errorf("failed to store ability catalog: %w", err)
+	}
+
+	entries := make([]*ActionEntry, len(doc.Actions))
+	for i, entry := range doc.Actions {
+		copied := *entry
+		copied.MatchID = doc.Match.ID
+		entries[i] = &copied
+	}
+
+	return s.repo.ImportMatch(ctx, record, entries, snapshots)
+}
+
+func sameUser(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func sameTeam(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
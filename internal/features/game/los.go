@@ -0,0 +1,86 @@
+package game
+
+// LineOfSight casts a ray between the centres of two tiles and reports
+// whether it reaches the destination. Only the tiles strictly between the
+// endpoints are checked: walls always block, other tiles block when blocked
+// returns true.
+func LineOfSight(board *Board, from, to Position, blocked func(Position) bool) bool {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+
+	// Bresenham walk; integer only so every client and replay agrees
+	err := dx + dy
+	p := from
+	for {
+		if p != from && p != to {
+			if !board.IsWalkable(p) || blocked(p) {
+				return false
+			}
+		}
+		if p == to {
+			return true
+		}
+
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			p.Y += sy
+		}
+	}
+}
+
+// checkTarget validates aiming an ability from a tile at a target tile,
+// independently of the caster's remaining AP
+func checkTarget(state *MatchState, caster *Character, ability Ability, from, target Position) error {
+	if !state.Board.InBounds(target) {
+		return ErrOutOfBounds
+	}
+	if !state.Board.IsWalkable(target) {
+		return ErrTileBlocked
+	}
+	if from.Distance(target) > ability.Range {
+		return ErrOutOfRange
+	}
+
+	// Units other than the caster block the ray
+	blocked := func(p Position) bool {
+		c := state.CharacterAt(p)
+		return c != nil && c != caster
+	}
+	if !LineOfSight(state.Board, from, target, blocked) {
+		return ErrNoLineOfSight
+	}
+
+	if ability.AOERadius == nil {
+		if c := state.CharacterAt(target); c == nil || c == caster {
+			return ErrNoTarget
+		}
+	}
+
+	return nil
+}
+
+// LegalTargets returns every tile the caster could aim the ability at when
+// standing on from, in row-major order
+func LegalTargets(state *MatchState, caster *Character, ability Ability, from Position) []Position {
+	var targets []Position
+	for y := from.Y - ability.Range; y <= from.Y+ability.Range; y++ {
+		for x := from.X - ability.Range; x <= from.X+ability.Range; x++ {
+			target := Position{X: x, Y: y}
+			if checkTarget(state, caster, ability, from, target) == nil {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
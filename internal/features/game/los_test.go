@@ -0,0 +1,146 @@
+package game
+
+import "testing"
+
+func TestLineOfSight(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    *Board
+		from, to Position
+		blocked  func(Position) bool
+		want     bool
+	}{
+		{
+			name:    "same tile",
+			board:   testBoard("..."),
+			from:    Position{X: 1, Y: 0},
+			to:      Position{X: 1, Y: 0},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name:    "adjacent",
+			board:   testBoard(".."),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 1, Y: 0},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name:    "open row",
+			board:   testBoard("....."),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 4, Y: 0},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name:    "wall in between",
+			board:   testBoard("..#.."),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 4, Y: 0},
+			blocked: occupied(),
+			want:    false,
+		},
+		{
+			name:    "unit in between",
+			board:   testBoard("....."),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 4, Y: 0},
+			blocked: occupied(Position{X: 2, Y: 0}),
+			want:    false,
+		},
+		{
+			name:    "rough terrain does not block",
+			board:   testBoard("..~.."),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 4, Y: 0},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name:    "endpoints are not checked",
+			board:   testBoard("#...#"),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 4, Y: 0},
+			blocked: occupied(Position{X: 0, Y: 0}, Position{X: 4, Y: 0}),
+			want:    true,
+		},
+		{
+			name: "diagonal through a wall",
+			board: testBoard(
+				"...",
+				".#.",
+				"...",
+			),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 2, Y: 2},
+			blocked: occupied(),
+			want:    false,
+		},
+		{
+			name: "diagonal past walls on both sides",
+			board: testBoard(
+				".#.",
+				"#.#",
+				".#.",
+			),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 2, Y: 2},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name: "shallow line steps down halfway",
+			board: testBoard(
+				"..#.",
+				".#..",
+			),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 3, Y: 1},
+			blocked: occupied(),
+			want:    true,
+		},
+		{
+			name: "shallow line blocked after stepping down",
+			board: testBoard(
+				"....",
+				"..#.",
+			),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 3, Y: 1},
+			blocked: occupied(),
+			want:    false,
+		},
+		{
+			name: "shallow line blocked before stepping down",
+			board: testBoard(
+				".#..",
+				"....",
+			),
+			from:    Position{X: 0, Y: 0},
+			to:      Position{X: 3, Y: 1},
+			blocked: occupied(),
+			want:    false,
+		},
+		{
+			name: "reverse direction",
+			board: testBoard(
+				"....",
+				"..#.",
+			),
+			from:    Position{X: 3, Y: 1},
+			to:      Position{X: 0, Y: 0},
+			blocked: occupied(),
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineOfSight(tt.board, tt.from, tt.to, tt.blocked); got != tt.want {
+				t.Errorf("LineOfSight(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
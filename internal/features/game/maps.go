@@ -0,0 +1,95 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// MapArena is the map matches are played on unless their rules pick another
+const MapArena = "arena"
+
+var ErrUnknownMap = errors.New("unknown map")
+
+// maps builds the boards matches can be played on, by name
+var maps = map[string]func() *Board{
+	MapArena:  DefaultBoard,
+	"open":    openBoard,
+	"pillars": pillarsBoard,
+}
+
+// NewMap builds the board of a map; an empty name is the arena
+func NewMap(name string) (*Board, error) {
+	if name == "" {
+		name = MapArena
+	}
+	build, ok := maps[name]
+	if !ok {
+		return nil, ErrUnknownMap
+	}
+	board := build()
+	if err := board.Validate(); err != nil {
+		return nil, fmt.Errorf("map %s: %w", name, err)
+	}
+	return board, nil
+}
+
+// MapNames lists the available maps in alphabetical order
+func MapNames() []string {
+	names := make([]string, 0, len(maps))
+	for name := range maps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openBoard is a 10x10 field without cover
+func openBoard() *Board {
+	return NewBoard(10, 10)
+}
+
+// pillarsBoard is a 12x10 hall with rows of pillars breaking line of sight
+func pillarsBoard() *Board {
+	b := NewBoard(12, 10)
+	for _, x := range []int{3, 5, 6, 8} {
+		for _, y := range []int{2, 7} {
+			b.Set(Position{X: x, Y: y}, TerrainWall)
+		}
+	}
+	for _, p := range []Position{{X: 5, Y: 4}, {X: 6, Y: 5}} {
+		b.Set(p, TerrainWall)
+	}
+	for _, p := range []Position{{X: 4, Y: 4}, {X: 4, Y: 5}, {X: 7, Y: 4}, {X: 7, Y: 5}} {
+		b.Set(p, TerrainRough)
+	}
+	return b
+}
+
+// SetupMatch builds the starting state of a match on the map its rules pick,
+// with only the abilities they allow
+func SetupMatch(matchID uuid.UUID, rules Rules, abilities map[string]Ability, participants []Participant) (*MatchState, error) {
+	board, err := NewMap(rules.Map)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := abilities
+	if len(rules.AllowedAbilities) > 0 {
+		allowed = make(map[string]Ability, len(rules.AllowedAbilities))
+		for _, id := range rules.AllowedAbilities {
+			if a, ok := abilities[id]; ok {
+				allowed[id] = a
+			}
+		}
+	}
+
+	state, err := NewMatchState(matchID, board, allowed, participants)
+	if err != nil {
+		return nil, err
+	}
+	state.Rules = rules
+	return state, nil
+}
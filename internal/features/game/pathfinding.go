@@ -0,0 +1,113 @@
+package game
+
+import "container/heap"
+
+// neighbours lists the four orthogonal steps in a fixed order so that
+// equally short paths are always resolved the same way
+var neighbours = []Position{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+
+// FindPath returns the cheapest route from start to goal using A*, excluding
+// the start tile, along with its total AP cost. Tiles for which blocked
+// returns true are never entered.
+func FindPath(board *Board, start, goal Position, blocked func(Position) bool) ([]Position, int, bool) {
+	if start == goal {
+		return nil, 0, true
+	}
+	if !board.IsWalkable(goal) || blocked(goal) {
+		return nil, 0, false
+	}
+
+	open := &nodeQueue{}
+	heap.Push(open, &node{pos: start, f: start.Distance(goal)})
+
+	cameFrom := map[Position]Position{}
+	gScore := map[Position]int{start: 0}
+	seq := 0
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*node)
+		if current.pos == goal {
+			return reconstructPath(cameFrom, start, goal), gScore[goal], true
+		}
+		// Skip stale queue entries superseded by a cheaper route
+		if current.g > gScore[current.pos] {
+			continue
+		}
+
+		for _, d := range neighbours {
+			next := Position{X: current.pos.X + d.X, Y: current.pos.Y + d.Y}
+			if !board.IsWalkable(next) || blocked(next) {
+				continue
+			}
+
+			g := current.g + board.StepCost(next)
+			if best, seen := gScore[next]; seen && g >= best {
+				continue
+			}
+
+			gScore[next] = g
+			cameFrom[next] = current.pos
+			seq++
+			heap.Push(open, &node{pos: next, g: g, f: g + next.Distance(goal), seq: seq})
+		}
+	}
+
+	return nil, 0, false
+}
+
+// PathCost validates a client supplied path and returns its AP cost. Every
+// step must be orthogonally adjacent to the previous one and enterable.
+func PathCost(board *Board, start Position, path []Position, blocked func(Position) bool) (int, bool) {
+	cost := 0
+	prev := start
+	for _, step := range path {
+		if prev.Distance(step) != 1 || !board.IsWalkable(step) || blocked(step) {
+			return 0, false
+		}
+		cost += board.StepCost(step)
+		prev = step
+	}
+	return cost, len(path) > 0
+}
+
+func reconstructPath(cameFrom map[Position]Position, start, goal Position) []Position {
+	var path []Position
+	for p := goal; p != start; p = cameFrom[p] {
+		path = append(path, p)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// node is an entry of the A* open set
+type node struct {
+	pos Position
+	g   int
+	f   int
+	seq int
+}
+
+// nodeQueue orders nodes by estimated total cost, then by insertion order
+type nodeQueue []*node
+
+func (q nodeQueue) Len() int { return len(q) }
+
+func (q nodeQueue) Less(i, j int) bool {
+	if q[i].f != q[j].f {
+		return q[i].f < q[j].f
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q nodeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *nodeQueue) Push(x any) { *q = append(*q, x.(*node)) }
+
+func (q *nodeQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
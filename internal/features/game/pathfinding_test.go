@@ -0,0 +1,252 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testBoard builds a board from rows of tiles: '.' floor, '~' rough, '#' wall
+func testBoard(rows ...string) *Board {
+	b := NewBoard(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case '~':
+				b.Set(Position{X: x, Y: y}, TerrainRough)
+			case '#':
+				b.Set(Position{X: x, Y: y}, TerrainWall)
+			}
+		}
+	}
+	return b
+}
+
+// withMoveCost overrides the AP cost of entering a terrain
+func withMoveCost(b *Board, terrain Terrain, cost int) *Board {
+	b.MoveCosts = map[Terrain]int{terrain: cost}
+	return b
+}
+
+// occupied returns a blocked func for the given tiles
+func occupied(tiles ...Position) func(Position) bool {
+	return func(p Position) bool {
+		for _, t := range tiles {
+			if t == p {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		board       *Board
+		start, goal Position
+		blocked     func(Position) bool
+		wantPath    []Position
+		wantCost    int
+		wantOK      bool
+	}{
+		{
+			name:     "same tile",
+			board:    testBoard("..."),
+			start:    Position{X: 1, Y: 0},
+			goal:     Position{X: 1, Y: 0},
+			blocked:  occupied(),
+			wantPath: nil,
+			wantCost: 0,
+			wantOK:   true,
+		},
+		{
+			name:     "straight line",
+			board:    testBoard("...."),
+			start:    Position{X: 0, Y: 0},
+			goal:     Position{X: 3, Y: 0},
+			blocked:  occupied(),
+			wantPath: []Position{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
+			wantCost: 3,
+			wantOK:   true,
+		},
+		{
+			name: "around a wall",
+			board: testBoard(
+				"...",
+				".#.",
+				"...",
+			),
+			start:    Position{X: 1, Y: 0},
+			goal:     Position{X: 1, Y: 2},
+			blocked:  occupied(),
+			wantPath: []Position{{X: 2, Y: 0}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 1, Y: 2}},
+			wantCost: 4,
+			wantOK:   true,
+		},
+		{
+			name: "detour around costly terrain",
+			board: withMoveCost(testBoard(
+				".~.",
+				"...",
+			), TerrainRough, 5),
+			start:    Position{X: 0, Y: 0},
+			goal:     Position{X: 2, Y: 0},
+			blocked:  occupied(),
+			wantPath: []Position{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 0}},
+			wantCost: 4,
+			wantOK:   true,
+		},
+		{
+			name:     "through rough terrain when there is no detour",
+			board:    testBoard(".~."),
+			start:    Position{X: 0, Y: 0},
+			goal:     Position{X: 2, Y: 0},
+			blocked:  occupied(),
+			wantPath: []Position{{X: 1, Y: 0}, {X: 2, Y: 0}},
+			wantCost: 3,
+			wantOK:   true,
+		},
+		{
+			name: "around an occupied tile",
+			board: testBoard(
+				"...",
+				"...",
+			),
+			start:    Position{X: 0, Y: 0},
+			goal:     Position{X: 2, Y: 0},
+			blocked:  occupied(Position{X: 1, Y: 0}),
+			wantPath: []Position{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 0}},
+			wantCost: 4,
+			wantOK:   true,
+		},
+		{
+			name:    "goal is a wall",
+			board:   testBoard("..#"),
+			start:   Position{X: 0, Y: 0},
+			goal:    Position{X: 2, Y: 0},
+			blocked: occupied(),
+		},
+		{
+			name:    "goal is occupied",
+			board:   testBoard("..."),
+			start:   Position{X: 0, Y: 0},
+			goal:    Position{X: 2, Y: 0},
+			blocked: occupied(Position{X: 2, Y: 0}),
+		},
+		{
+			name:    "goal is out of bounds",
+			board:   testBoard("..."),
+			start:   Position{X: 0, Y: 0},
+			goal:    Position{X: 3, Y: 0},
+			blocked: occupied(),
+		},
+		{
+			name: "goal is walled in",
+			board: testBoard(
+				"..#.",
+				"..#.",
+			),
+			start:   Position{X: 0, Y: 0},
+			goal:    Position{X: 3, Y: 1},
+			blocked: occupied(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cost, ok := FindPath(tt.board, tt.start, tt.goal, tt.blocked)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(path, tt.wantPath) {
+				t.Errorf("path = %v, want %v", path, tt.wantPath)
+			}
+			if cost != tt.wantCost {
+				t.Errorf("cost = %d, want %d", cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestFindPathIsDeterministic(t *testing.T) {
+	board := NewBoard(6, 6)
+	start, goal := Position{X: 0, Y: 0}, Position{X: 5, Y: 5}
+
+	want, _, _ := FindPath(board, start, goal, occupied())
+	for i := 0; i < 20; i++ {
+		got, _, _ := FindPath(board, start, goal, occupied())
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: path = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestPathCost(t *testing.T) {
+	board := testBoard(
+		".~.",
+		".#.",
+	)
+	start := Position{X: 0, Y: 0}
+
+	tests := []struct {
+		name     string
+		path     []Position
+		blocked  func(Position) bool
+		wantCost int
+		wantOK   bool
+	}{
+		{
+			name:     "floor step",
+			path:     []Position{{X: 0, Y: 1}},
+			blocked:  occupied(),
+			wantCost: 1,
+			wantOK:   true,
+		},
+		{
+			name:     "rough then floor",
+			path:     []Position{{X: 1, Y: 0}, {X: 2, Y: 0}},
+			blocked:  occupied(),
+			wantCost: 3,
+			wantOK:   true,
+		},
+		{
+			name:    "empty path",
+			path:    nil,
+			blocked: occupied(),
+		},
+		{
+			name:    "diagonal step",
+			path:    []Position{{X: 1, Y: 1}},
+			blocked: occupied(),
+		},
+		{
+			name:    "jump",
+			path:    []Position{{X: 2, Y: 0}},
+			blocked: occupied(),
+		},
+		{
+			name:    "into a wall",
+			path:    []Position{{X: 0, Y: 1}, {X: 1, Y: 1}},
+			blocked: occupied(),
+		},
+		{
+			name:    "through an occupied tile",
+			path:    []Position{{X: 1, Y: 0}, {X: 2, Y: 0}},
+			blocked: occupied(Position{X: 1, Y: 0}),
+		},
+		{
+			name:    "off the board",
+			path:    []Position{{X: -1, Y: 0}},
+			blocked: occupied(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, ok := PathCost(board, start, tt.path, tt.blocked)
+			if ok != tt.wantOK || cost != tt.wantCost {
+				t.Errorf("PathCost = (%d, %v), want (%d, %v)", cost, ok, tt.wantCost, tt.wantOK)
+			}
+		})
+	}
+}
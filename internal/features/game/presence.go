@@ -0,0 +1,200 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// awayPlayer is a disconnected player whose slot is being held
+type awayPlayer struct {
+	timer    *time.Timer
+	deadline time.Time
+}
+
+// Resumption is what a reconnecting player needs to pick a match back up:
+// the full state and every event after the last one they saw
+type Resumption struct {
+	State      *MatchState `json:"state"`
+	Deadline   time.Time   `json:"deadline"`
+	TurnPaused bool        `json:"turn_paused"`
+	Seq        int         `json:"seq"`
+	Events     []Event     `json:"events"`
+}
+
+// Disconnected holds the slot of a player who lost their last connection in
+// every running match they play, for the match's grace period
+func (s *Service) Disconnected(userID uuid.UUID) {
+	for _, lm := range s.playing(userID) {
+		lm.mu.Lock()
+		s.markAway(lm, userID)
+		lm.mu.Unlock()
+	}
+}
+
+// Connected cancels the grace period of a player who came back
+func (s *Service) Connected(userID uuid.UUID) {
+	for _, lm := range s.playing(userID) {
+		lm.mu.Lock()
+		s.markBack(lm, userID)
+		lm.mu.Unlock()
+	}
+}
+
+// Resume returns the state of a running match and the events a player
+// missed after the action with log seq lastSeq. Events after the same action,
+// such as the turn start, are covered by the state and deadline.
+func (s *Service) Resume(matchID, userID uuid.UUID, lastSeq int) (*Resumption, error) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if _, ok := lm.state.Characters[userID]; !ok {
+		return nil, ErrNotParticipant
+	}
+	s.markBack(lm, userID)
+
+	resumption := &Resumption{
+		State:      lm.state.Clone(),
+		Deadline:   lm.deadline,
+		TurnPaused: lm.paused != nil,
+		Seq:        lm.seq,
+		Events:     []Event{},
+	}
+	for _, event := range lm.events {
+		if event.Seq > lastSeq {
+			resumption.Events = append(resumption.Events, event)
+		}
+	}
+
+	return resumption, nil
+}
+
+// playing returns the running matches a user takes part in
+func (s *Service) playing(userID uuid.UUID) []*liveMatch {
+	s.mu.RLock()
+	all := make([]*liveMatch, 0, len(s.matches))
+	for _, lm := range s.matches {
+		all = append(all, lm)
+	}
+	s.mu.RUnlock()
+
+	var matches []*liveMatch
+	for _, lm := range all {
+		lm.mu.Lock()
+		if _, ok := lm.state.Characters[userID]; ok && lm.state.Status == StatusActive {
+			matches = append(matches, lm)
+		}
+		lm.mu.Unlock()
+	}
+	return matches
+}
+
+// markAway starts the grace period of a disconnected player and, under the
+// pause policy, stops their turn clock. The caller must hold the match lock.
+func (s *Service) markAway(lm *liveMatch, userID uuid.UUID) {
+	state := lm.state
+	grace := state.Rules.DisconnectGrace()
+	if _, away := lm.away[userID]; away || state.Status != StatusActive || !state.Characters[userID].IsAlive() {
+		return
+	}
+
+	player := awayPlayer{deadline: time.Now().Add(grace)}
+	if grace > 0 {
+		matchID := state.MatchID
+		player.timer = time.AfterFunc(grace, func() {
+			s.expireGrace(matchID, userID)
+		})
+	}
+	lm.away[userID] = player
+
+	if state.Rules.TimerPolicy == TimerPolicyPause && state.ActiveUserID() == userID {
+		s.pauseTimer(lm, time.Until(lm.deadline))
+	}
+
+	event := PresenceEvent{MatchID: state.MatchID, UserID: userID, TurnPaused: lm.paused != nil, Deadline: lm.deadline}
+	if grace > 0 {
+		event.GraceDeadline = &player.deadline
+	}
+	s.notify(lm, EventDisconnected, event)
+
+	slog.Info("Player disconnected from match", "matchId", state.MatchID, "userId", userID, "grace", grace)
+}
+
+// markBack ends the grace period of a player and restarts a turn clock that
+// was paused for them. The caller must hold the match lock.
+func (s *Service) markBack(lm *liveMatch, userID uuid.UUID) {
+	player, away := lm.away[userID]
+	if !away {
+		return
+	}
+	if player.timer != nil {
+		player.timer.Stop()
+	}
+	delete(lm.away, userID)
+
+	state := lm.state
+	if lm.paused != nil && state.ActiveUserID() == userID {
+		s.armTimer(lm, *lm.paused)
+	}
+
+	s.notify(lm, EventReconnected, PresenceEvent{MatchID: state.MatchID, UserID: userID, TurnPaused: lm.paused != nil, Deadline: lm.deadline})
+
+	slog.Info("Player reconnected to match", "matchId", state.MatchID, "userId", userID)
+}
+
+// pauseTimer stops the turn clock, keeping the time that was left.
+// The caller must hold the match lock.
+func (s *Service) pauseTimer(lm *liveMatch, left time.Duration) {
+	if lm.timer != nil {
+		lm.timer.Stop()
+	}
+	if left < 0 {
+		left = 0
+	}
+	lm.paused = &left
+	lm.deadline = time.Time{}
+	s.saveClock(lm)
+}
+
+// expireGrace forfeits a player who did not come back in time
+func (s *Service) expireGrace(matchID, userID uuid.UUID) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	// The player may have come back, possibly dropping again since
+	player, away := lm.away[userID]
+	if !away || time.Now().Before(player.deadline) || lm.state.Status != StatusActive {
+		return
+	}
+	delete(lm.away, userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	slog.Info("Disconnect grace period expired", "matchId", matchID, "userId", userID)
+
+	if _, err := s.apply(ctx, lm, Action{Type: ActionAbandon, UserID: userID}); err != nil {
+		slog.Error("Failed to apply abandonment", "error", err, "matchId", matchID, "userId", userID)
+		lm.away[userID] = awayPlayer{
+			deadline: time.Now(),
+			timer:    time.AfterFunc(commitRetryDelay, func() { s.expireGrace(matchID, userID) }),
+		}
+	}
+}
+
+// InMatch reports whether a user plays in a running match
+func (s *Service) InMatch(userID uuid.UUID) bool {
+	return len(s.playing(userID)) > 0
+}
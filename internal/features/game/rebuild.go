@@ -0,0 +1,291 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// Rebuild metrics, published on /debug/vars
+var (
+	rebuildsTotal    = expvar.NewInt("game_rebuilds")
+	divergencesTotal = expvar.NewInt("game_rebuild_divergences")
+)
+
+// Divergence is a field where a rebuilt state disagrees with a stored snapshot
+type Divergence struct {
+	TurnNo    int         `json:"turn_no"`
+	UserID    uuid.UUID   `json:"user_id"`
+	AbilityID string      `json:"ability_id,omitempty"`
+	Field     string      `json:"field"`
+	Expected  interface{} `json:"expected"`
+	Actual    interface{} `json:"actual"`
+}
+
+// Fold applies a match's logged actions in order on top of its initial state
+// and returns the result of each one. onTurn, when set, is called with the
+// initial state and again every time an action starts a new turn, which is
+// when the live match takes snapshots.
+func Fold(state *MatchState, entries []*ActionEntry, onTurn func(*MatchState)) ([]*ActionResult, error) {
+	if onTurn != nil {
+		onTurn(state)
+	}
+
+	results := make([]*ActionResult, 0, len(entries))
+	for _, entry := range entries {
+		turnNo := state.TurnNo
+		if entry.TurnNo != turnNo {
+			return nil, fmt.Errorf("action %d was logged on turn %d but the rebuilt match is on turn %d", entry.Seq, entry.TurnNo, turnNo)
+		}
+		result, err := ApplyAction(state, entry.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("action %d (%s) no longer applies: %w", entry.Seq, entry.ActionType, err)
+		}
+		results = append(results, result)
+		if onTurn != nil && state.Status == StatusActive && state.TurnNo != turnNo {
+			onTurn(state)
+		}
+	}
+
+	return results, nil
+}
+
+// rebuiltMatch is a match reconstructed from its stored log
+type rebuiltMatch struct {
+	record      *MatchRecord
+	initial     *MatchState
+	state       *MatchState
+	entries     []*ActionEntry
+	results     []*ActionResult
+	divergences []Divergence
+}
+
+// applied maps the actions sent with a client action id to their results;
+// rolls are seeded, so these are the results the players originally saw
+func (m *rebuiltMatch) applied() map[actionKey]*ActionResult {
+	applied := make(map[actionKey]*ActionResult)
+	for i, entry := range m.entries {
+		if entry.Payload.ClientActionID != "" {
+			applied[actionKey{entry.UserID, entry.Payload.ClientActionID}] = m.results[i]
+		}
+	}
+	return applied
+}
+
+// events regenerates the action events the players were sent, numbered like
+// the live ones, so a client can catch up on actions from before a restart
+func (m *rebuiltMatch) events() []Event {
+	state := m.initial.Clone()
+	events := make([]Event, 0, len(m.entries))
+	for _, entry := range m.entries {
+		turnNo := state.TurnNo
+		result, err := ApplyAction(state, entry.Payload)
+		if err != nil {
+			// The log already folded once, so this cannot happen
+			break
+		}
+		msgType, data := actionEvent(state, entry.Payload, turnNo, result)
+		events = append(events, Event{Seq: entry.Seq, Type: msgType, Data: data})
+	}
+	return events
+}
+
+// Rebuild reconstructs a match by folding its action log and cross-checks
+// every turn against character_snapshots and ability_snapshots. Divergences
+// are logged and counted; they do not make the rebuild fail.
+func (s *Service) Rebuild(ctx context.Context, matchID uuid.UUID) (*MatchState, []Divergence, error) {
+	rebuilt, err := s.rebuild(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rebuilt.state, rebuilt.divergences, nil
+}
+
+func (s *Service) rebuild(ctx context.Context, matchID uuid.UUID) (*rebuiltMatch, error) {
+	record, err := s.repo.GetMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListActions(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match actions: %w", err)
+	}
+	characterSnaps, abilitySnaps, err := s.repo.ListSnapshots(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match snapshots: %w", err)
+	}
+
+	state, err := s.initialState(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	initial := state.Clone()
+	checker := newSnapshotChecker(characterSnaps, abilitySnaps)
+	results, err := Fold(state, entries, checker.check)
+	if err != nil {
+		return nil, err
+	}
+
+	rebuildsTotal.Add(1)
+	divergencesTotal.Add(int64(len(checker.divergences)))
+	for _, d := range checker.divergences {
+		slog.Warn("Rebuilt match diverges from snapshot",
+			"matchId", matchID, "turnNo", d.TurnNo, "userId", d.UserID, "abilityId", d.AbilityID,
+			"field", d.Field, "expected", d.Expected, "actual", d.Actual)
+	}
+
+	return &rebuiltMatch{
+		record:      record,
+		initial:     initial,
+		state:       state,
+		entries:     entries,
+		results:     results,
+		divergences: checker.divergences,
+	}, nil
+}
+
+// initialState builds the state a match had before its first action, with
+// the abilities of the catalog version it was played with
+func (s *Service) initialState(ctx context.Context, record *MatchRecord) (*MatchState, error) {
+	abilities, err := s.catalog.SpecsAt(ctx, record.CatalogVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ability catalog %q: %w", record.CatalogVersion, err)
+	}
+
+	// Matches started before rules were stored ran on the defaults
+	rules := record.Rules
+	if rules.IsZero() {
+		rules = s.rules
+	}
+
+	state, err := SetupMatch(record.MatchID, rules, abilities, record.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Seed = record.Seed
+	state.CatalogVersion = record.CatalogVersion
+	state.Private = record.Private
+	state.HostUserID = record.HostUserID
+	state.SpectatingDisabled = record.SpectatingDisabled
+
+	return state, nil
+}
+
+// verify rebuilds an ended match in the background so divergences surface in
+// the logs and metrics
+func (s *Service) verify(matchID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	if _, _, err := s.Rebuild(ctx, matchID); err != nil {
+		slog.Error("Failed to rebuild match", "error", err, "matchId", matchID)
+	}
+}
+
+// snapshotKey identifies the snapshots of one user on one turn
+type snapshotKey struct {
+	TurnNo int
+	UserID uuid.UUID
+}
+
+// snapshotChecker compares rebuilt states with the stored snapshots of their turn
+type snapshotChecker struct {
+	characters  map[snapshotKey]CharacterSnapshot
+	abilities   map[snapshotKey][]AbilitySnapshot
+	turns       map[int]bool
+	divergences []Divergence
+}
+
+func newSnapshotChecker(characters []CharacterSnapshot, abilities []AbilitySnapshot) *snapshotChecker {
+	c := &snapshotChecker{
+		characters: make(map[snapshotKey]CharacterSnapshot, len(characters)),
+		abilities:  make(map[snapshotKey][]AbilitySnapshot),
+		turns:      make(map[int]bool),
+	}
+	for _, snap := range characters {
+		c.characters[snapshotKey{snap.TurnNo, snap.UserID}] = snap
+		c.turns[snap.TurnNo] = true
+	}
+	for _, snap := range abilities {
+		key := snapshotKey{snap.TurnNo, snap.UserID}
+		c.abilities[key] = append(c.abilities[key], snap)
+	}
+	return c
+}
+
+// check records how state differs from the snapshots of its turn. Turns
+// without snapshots, such as compacted ones, are skipped.
+func (c *snapshotChecker) check(state *MatchState) {
+	turnNo := state.TurnNo
+	if !c.turns[turnNo] {
+		return
+	}
+
+	for _, id := range state.TurnOrder {
+		char := state.Characters[id]
+		key := snapshotKey{turnNo, id}
+
+		snap, ok := c.characters[key]
+		if !ok {
+			c.diverge(turnNo, id, "", "snapshot", "present", "missing")
+			continue
+		}
+		c.compare(turnNo, id, "", "hp", snap.HP, char.HP)
+		c.compare(turnNo, id, "", "ap", snap.AP, char.AP)
+		c.compare(turnNo, id, "", "x", snap.X, char.X)
+		c.compare(turnNo, id, "", "y", snap.Y, char.Y)
+		c.compare(turnNo, id, "", "effects", encode(snap.Effects), encode(char.Effects))
+
+		seen := make(map[string]bool, len(char.AbilityUsage))
+		for _, abilitySnap := range c.abilities[key] {
+			seen[abilitySnap.AbilityID] = true
+			usage, ok := char.AbilityUsage[abilitySnap.AbilityID]
+			if !ok {
+				usage = &AbilityUsage{}
+			}
+			c.compare(turnNo, id, abilitySnap.AbilityID, "uses_this_turn", abilitySnap.UsesThisTurn, usage.UsesThisTurn)
+			c.compare(turnNo, id, abilitySnap.AbilityID, "per_target_uses", encode(abilitySnap.PerTargetUses), encode(usage.PerTargetUses))
+		}
+		for _, abilityID := range sortedKeys(char.AbilityUsage) {
+			if !seen[abilityID] {
+				c.diverge(turnNo, id, abilityID, "snapshot", "missing", "present")
+			}
+		}
+	}
+}
+
+func (c *snapshotChecker) compare(turnNo int, userID uuid.UUID, abilityID, field string, expected, actual interface{}) {
+	if expected != actual {
+		c.diverge(turnNo, userID, abilityID, field, expected, actual)
+	}
+}
+
+func (c *snapshotChecker) diverge(turnNo int, userID uuid.UUID, abilityID, field string, expected, actual interface{}) {
+	c.divergences = append(c.divergences, Divergence{
+		TurnNo:    turnNo,
+		UserID:    userID,
+		AbilityID: abilityID,
+		Field:     field,
+		Expected:  expected,
+		Actual:    actual,
+	})
+}
+
+// encode renders slices and maps comparably, treating nil and empty alike
+func encode(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	switch string(b) {
+	case "null", "[]", "{}":
+		return ""
+	}
+	return string(b)
+}
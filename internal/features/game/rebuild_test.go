@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// duel sets up two players facing each other on an open 5x3 board
+func duel(seed int64) (*MatchState, uuid.UUID, uuid.UUID) {
+	a, b := uuid.New(), uuid.New()
+	abilities := map[string]Ability{
+		"bolt": {ID: "bolt", BaseDamage: 10, APCost: 2, Range: 4},
+	}
+	state, err := NewMatchState(uuid.New(), NewBoard(5, 3), abilities, []Participant{
+		{UserID: a, StartingHP: 100, StartingAP: 6, StartX: 0, StartY: 1},
+		{UserID: b, StartingHP: 100, StartingAP: 6, StartX: 4, StartY: 1},
+	})
+	if err != nil {
+		panic(err)
+	}
+	state.Seed = seed
+	return state, a, b
+}
+
+// play applies actions to a live state and logs them the way the service does
+func play(t *testing.T, state *MatchState, actions []Action) ([]*ActionEntry, []*ActionResult) {
+	t.Helper()
+	var (
+		entries []*ActionEntry
+		results []*ActionResult
+	)
+	for i, action := range actions {
+		turnNo := state.TurnNo
+		result, err := ApplyAction(state, action)
+		if err != nil {
+			t.Fatalf("action %d (%s): %v", i+1, action.Type, err)
+		}
+		entries = append(entries, &ActionEntry{Seq: i + 1, UserID: action.UserID, TurnNo: turnNo, ActionType: action.Type, Payload: action})
+		results = append(results, result)
+	}
+	return entries, results
+}
+
+// snapshot serializes the exported state for comparison
+func snapshot(t *testing.T, state *MatchState) string {
+	t.Helper()
+	raw, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func TestFoldReproducesLivePlay(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    int64
+		actions func(a, b uuid.UUID) []Action
+		turns   int
+	}{
+		{
+			name: "casts and end turns",
+			seed: 1,
+			actions: func(a, b uuid.UUID) []Action {
+				return []Action{
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionEndTurn, UserID: a},
+					{Type: ActionCast, UserID: b, AbilityID: "bolt", Target: Position{X: 0, Y: 1}},
+					{Type: ActionEndTurn, UserID: b},
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionEndTurn, UserID: a},
+				}
+			},
+			turns: 4,
+		},
+		{
+			name: "moves between casts",
+			seed: 42,
+			actions: func(a, b uuid.UUID) []Action {
+				return []Action{
+					{Type: ActionMove, UserID: a, Target: Position{X: 1, Y: 0}},
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionEndTurn, UserID: a},
+					{Type: ActionMove, UserID: b, Target: Position{X: 3, Y: 2}, Path: []Position{{X: 3, Y: 1}, {X: 3, Y: 2}}},
+					{Type: ActionCast, UserID: b, AbilityID: "bolt", Target: Position{X: 1, Y: 0}},
+					{Type: ActionCast, UserID: b, AbilityID: "bolt", Target: Position{X: 1, Y: 0}},
+					{Type: ActionEndTurn, UserID: b},
+				}
+			},
+			turns: 3,
+		},
+		{
+			name: "surrender ends the match",
+			seed: 7,
+			actions: func(a, b uuid.UUID) []Action {
+				return []Action{
+					{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+					{Type: ActionEndTurn, UserID: a},
+					{Type: ActionSurrender, UserID: a},
+				}
+			},
+			turns: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, a, b := duel(tt.seed)
+			initial := live.Clone()
+			entries, want := play(t, live, tt.actions(a, b))
+
+			// Fold twice to make sure nothing but the log and the seed matters
+			for run := 0; run < 2; run++ {
+				state := initial.Clone()
+				turns := 0
+				got, err := Fold(state, entries, func(*MatchState) { turns++ })
+				if err != nil {
+					t.Fatalf("run %d: %v", run, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("run %d: results differ from live play", run)
+				}
+				if snapshot(t, state) != snapshot(t, live) {
+					t.Errorf("run %d: folded state differs from live state", run)
+				}
+				if turns != tt.turns {
+					t.Errorf("run %d: onTurn called %d times, want %d", run, turns, tt.turns)
+				}
+			}
+		})
+	}
+}
+
+func TestFoldRejectsBrokenLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(entries []*ActionEntry, a, b uuid.UUID)
+		wantErr string
+	}{
+		{
+			name:    "action logged on the wrong turn",
+			tamper:  func(entries []*ActionEntry, a, b uuid.UUID) { entries[1].TurnNo = 2 },
+			wantErr: "logged on turn 2",
+		},
+		{
+			name: "action out of turn",
+			tamper: func(entries []*ActionEntry, a, b uuid.UUID) {
+				entries[0].Payload.UserID = b
+			},
+			wantErr: "no longer applies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, a, b := duel(1)
+			initial := live.Clone()
+			entries, _ := play(t, live, []Action{
+				{Type: ActionCast, UserID: a, AbilityID: "bolt", Target: Position{X: 4, Y: 1}},
+				{Type: ActionEndTurn, UserID: a},
+			})
+			tt.tamper(entries, a, b)
+
+			_, err := Fold(initial.Clone(), entries, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recover reloads every match that was active when the server stopped and
+// resumes its turn timer with the time its player had left. Players count as
+// disconnected until they open a connection again. Matches that cannot be
+// rebuilt are logged and left as they are so one bad log does not keep the
+// others from resuming.
+func (s *Service) Recover(ctx context.Context) (int, error) {
+	ids, err := s.repo.ListActiveMatchIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active matches: %w", err)
+	}
+
+	recovered := 0
+	for _, matchID := range ids {
+		if err := s.recoverMatch(ctx, matchID); err != nil {
+			slog.Error("Failed to recover match", "error", err, "matchId", matchID)
+			continue
+		}
+		recovered++
+	}
+
+	return recovered, nil
+}
+
+func (s *Service) recoverMatch(ctx context.Context, matchID uuid.UUID) error {
+	rebuilt, err := s.rebuild(ctx, matchID)
+	if err != nil {
+		return err
+	}
+
+	state := rebuilt.state
+	if state.Status != StatusActive {
+		return fmt.Errorf("match log ends with status %q", state.Status)
+	}
+
+	lm := newLiveMatch(state)
+	lm.applied = rebuilt.applied()
+	lm.events = rebuilt.events()
+	if n := len(rebuilt.entries); n > 0 {
+		lm.seq = rebuilt.entries[n-1].Seq
+	}
+
+	s.mu.Lock()
+	if _, exists := s.matches[matchID]; exists {
+		s.mu.Unlock()
+		return ErrMatchAlreadyRunning
+	}
+	s.matches[matchID] = lm
+	s.mu.Unlock()
+
+	remaining := timeLeft(rebuilt)
+
+	lm.mu.Lock()
+	s.notifier().JoinMatch(matchID, AudiencePlayers, state.TurnOrder...)
+	s.armTimer(lm, remaining)
+	// Nobody is connected yet, so every player's slot is held for the grace
+	// period until they come back, like after any other disconnect
+	for _, userID := range state.TurnOrder {
+		if !state.Characters[userID].IsBot {
+			s.markAway(lm, userID)
+		}
+	}
+	s.scheduleBot(lm)
+	lm.mu.Unlock()
+
+	slog.Info("Match recovered", "matchId", matchID, "turnNo", state.TurnNo, "actions", len(rebuilt.entries), "remaining", remaining)
+	return nil
+}
+
+// timeLeft returns how long the current turn of a rebuilt match has left
+// according to its stored clock. A running clock kept running while the server
+// was down, so a turn that ran out in the meantime times out as soon as the
+// timer is armed; a paused clock stayed paused. Without a clock for the
+// current turn the time is counted from when the turn started.
+func timeLeft(rebuilt *rebuiltMatch) time.Duration {
+	var left time.Duration
+	switch clock := rebuilt.record.Clock; {
+	case clock != nil && clock.TurnNo == rebuilt.state.TurnNo && clock.Paused != nil:
+		left = *clock.Paused
+	case clock != nil && clock.TurnNo == rebuilt.state.TurnNo && clock.Deadline != nil:
+		left = time.Until(*clock.Deadline)
+	default:
+		left = rebuilt.state.Rules.TurnTimeout() - time.Since(turnStartedAt(rebuilt))
+	}
+	if left < 0 {
+		left = 0
+	}
+	return left
+}
+
+// turnStartedAt returns when the current turn of a rebuilt match began: the
+// time of the last action logged on an earlier turn, or the match start
+func turnStartedAt(rebuilt *rebuiltMatch) time.Time {
+	startedAt := rebuilt.record.StartedAt
+	for _, entry := range rebuilt.entries {
+		if entry.TurnNo < rebuilt.state.TurnNo {
+			startedAt = entry.CreatedAt
+		}
+	}
+	return startedAt
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrMatchNotEnded = errors.New("match has not ended")
+
+// Replay is the recorded course of an ended match
+type Replay struct {
+	MatchID      uuid.UUID     `json:"match_id"`
+	Seed         int64         `json:"seed"`
+	Rules        Rules         `json:"rules"`
+	StartedAt    time.Time     `json:"started_at"`
+	Participants []Participant `json:"participants"`
+	Turns        int           `json:"turns"`
+	Result       *MatchResult  `json:"result"`
+	Timeline     []ReplayEvent `json:"timeline"`
+}
+
+// ReplayEvent is a logged action together with the outcome it had
+type ReplayEvent struct {
+	Seq       int           `json:"seq"`
+	TurnNo    int           `json:"turn_no"`
+	CreatedAt time.Time     `json:"created_at"`
+	Result    *ActionResult `json:"result"`
+}
+
+// Replay loads the action timeline of an ended match. Outcomes are recomputed
+// from the seeded log, so they match what the players saw.
+func (s *Service) Replay(ctx context.Context, matchID uuid.UUID) (*Replay, error) {
+	record, err := s.repo.GetMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	// Active matches are not replayed so they cannot be scouted while running
+	if record.Status != StatusEnded {
+		return nil, ErrMatchNotEnded
+	}
+
+	entries, err := s.repo.ListActions(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match actions: %w", err)
+	}
+
+	state, err := s.initialState(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	results, err := Fold(state, entries, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &Replay{
+		MatchID:      matchID,
+		Seed:         record.Seed,
+		Rules:        state.Rules,
+		StartedAt:    record.StartedAt,
+		Participants: record.Participants,
+		Turns:        state.TurnNo,
+		Result:       state.Result,
+		Timeline:     make([]ReplayEvent, 0, len(entries)),
+	}
+	for i, entry := range entries {
+		replay.Timeline = append(replay.Timeline, ReplayEvent{
+			Seq:       entry.Seq,
+			TurnNo:    entry.TurnNo,
+			CreatedAt: entry.CreatedAt,
+			Result:    results[i],
+		})
+	}
+
+	return replay, nil
+}
+
+// SnapshotAt returns the characters of a replayed match at the start of the
+// latest snapshotted turn at or before turnNo, so playback can jump there
+// without replaying from the first turn
+func (s *Service) SnapshotAt(ctx context.Context, replay *Replay, turnNo int) ([]CharacterSnapshot, error) {
+	snapshots, err := s.repo.ListSnapshotsAt(ctx, replay.MatchID, turnNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshots: %w", err)
+	}
+	if len(snapshots) > 0 {
+		return snapshots, nil
+	}
+
+	// Without any snapshot the match is seeked to its starting positions
+	snapshots = make([]CharacterSnapshot, 0, len(replay.Participants))
+	for _, p := range replay.Participants {
+		snapshots = append(snapshots, CharacterSnapshot{
+			UserID: p.UserID,
+			TurnNo: 1,
+			HP:     p.StartingHP,
+			AP:     p.StartingAP,
+			X:      p.StartX,
+			Y:      p.StartY,
+		})
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,475 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ActionEntry is a single row of the match_actions log
+type ActionEntry struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	MatchID    uuid.UUID  `json:"match_id" db:"match_id"`
+	Seq        int        `json:"seq" db:"seq"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	TurnNo     int        `json:"turn_no" db:"turn_no"`
+	ActionType ActionType `json:"action_type" db:"action_type"`
+	Payload    Action     `json:"payload" db:"payload"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Commit is everything persisted for one resolved action, written atomically
+type Commit struct {
+	Entry *ActionEntry
+	// Snapshot is set when the action started a new turn
+	Snapshot *MatchState
+	// Result is set when the action ended the match
+	Result *MatchResult
+}
+
+// MatchRecord is the stored setup a match state is rebuilt from
+type MatchRecord struct {
+	MatchID            uuid.UUID     `json:"match_id"`
+	Status             MatchStatus   `json:"status"`
+	Seed               int64         `json:"seed"`
+	Rules              Rules         `json:"rules"`
+	CatalogVersion     string        `json:"catalog_version"`
+	StartedAt          time.Time     `json:"started_at"`
+	EndedAt            *time.Time    `json:"ended_at"`
+	WinnerUserID       *uuid.UUID    `json:"winner_user_id"`
+	WinnerTeam         *int          `json:"winner_team"`
+	EndReason          *EndReason    `json:"end_reason"`
+	Private            bool          `json:"private"`
+	HostUserID         *uuid.UUID    `json:"host_user_id"`
+	SpectatingDisabled bool          `json:"spectating_disabled"`
+	Queue              *string       `json:"queue"`
+	Ranked             bool          `json:"ranked"`
+	RatedAt            *time.Time    `json:"rated_at"`
+	Participants       []Participant `json:"participants"`
+
+	// Clock is the stored timer of the current turn, if any was written
+	Clock *TurnClock `json:"-"`
+}
+
+// TurnClock is the timer of a match's current turn: its deadline, or the
+// time that was left when it was paused
+type TurnClock struct {
+	TurnNo   int
+	Deadline *time.Time
+	Paused   *time.Duration
+}
+
+// CharacterSnapshot is a row of character_snapshots
+type CharacterSnapshot struct {
+	UserID  uuid.UUID      `json:"user_id" db:"user_id"`
+	TurnNo  int            `json:"turn_no" db:"turn_no"`
+	HP      int            `json:"hp" db:"hp"`
+	AP      int            `json:"ap" db:"ap"`
+	X       int            `json:"x" db:"x"`
+	Y       int            `json:"y" db:"y"`
+	Effects []StatusEffect `json:"effects" db:"effects"`
+}
+
+// AbilitySnapshot is a row of ability_snapshots
+type AbilitySnapshot struct {
+	UserID        uuid.UUID      `json:"user_id" db:"user_id"`
+	TurnNo        int            `json:"turn_no" db:"turn_no"`
+	AbilityID     string         `json:"ability_id" db:"ability_id"`
+	UsesThisTurn  int            `json:"uses_this_turn" db:"uses_this_turn"`
+	PerTargetUses map[string]int `json:"per_target_uses" db:"per_target_uses"`
+}
+
+// Repository interface for match persistence
+type Repository interface {
+	StartMatch(ctx context.Context, state *MatchState) error
+	Commit(ctx context.Context, commit *Commit) error
+	GetMatch(ctx context.Context, matchID uuid.UUID) (*MatchRecord, error)
+	ListActiveMatchIDs(ctx context.Context) ([]uuid.UUID, error)
+	ListActions(ctx context.Context, matchID uuid.UUID) ([]*ActionEntry, error)
+	ListSnapshots(ctx context.Context, matchID uuid.UUID) ([]CharacterSnapshot, []AbilitySnapshot, error)
+	ListSnapshotsAt(ctx context.Context, matchID uuid.UUID, turnNo int) ([]CharacterSnapshot, error)
+	ImportMatch(ctx context.Context, record *MatchRecord, entries []*ActionEntry, snapshots []*MatchState) error
+	SetSpectating(ctx context.Context, matchID uuid.UUID, disabled bool) error
+	SaveClock(ctx context.Context, matchID uuid.UUID, clock TurnClock) error
+}
+
+// PostgresRepository implements Repository
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL match persistence repository
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+const (
+	uniqueViolation = "23505"
+	// clientActionConstraint keeps a client action id from being logged twice
+	clientActionConstraint = "uq_match_actions_client_action"
+)
+
+// execer is the subset of pgx shared by the pool and transactions
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// StartMatch marks the match active, storing the seed its rolls derive from,
+// its rules, its turn order and the snapshots of the first turn
+func (r *PostgresRepository) StartMatch(ctx context.Context, state *MatchState) error {
+	rules, err := json.Marshal(state.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode rules: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE matches SET status = 'active', started_at = NOW(), seed = $2, rules = $3, turn_order = $4, catalog_version = $5,
+		is_private = $6, host_user_id = $7, spectating_disabled = $8
+		WHERE id = $1`
+	_, err = tx.Exec(ctx, query, state.MatchID, state.Seed, rules, state.TurnOrder, state.CatalogVersion,
+		state.Private, state.HostUserID, state.SpectatingDisabled)
+	if err != nil {
+		return err
+	}
+	if err := saveSnapshots(ctx, tx, state); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Commit appends the action to match_actions together with the snapshots or
+// result it produced, in a single transaction
+func (r *PostgresRepository) Commit(ctx context.Context, commit *Commit) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := appendAction(ctx, tx, commit.Entry); err != nil {
+		return err
+	}
+	if commit.Snapshot != nil {
+		if err := saveSnapshots(ctx, tx, commit.Snapshot); err != nil {
+			return err
+		}
+	}
+	if commit.Result != nil {
+		query := `UPDATE matches SET status = 'ended', ended_at = NOW(), winner_user_id = $2, winner_team = $3, end_reason = $4 WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, commit.Entry.MatchID, commit.Result.WinnerUserID, commit.Result.WinnerTeam, commit.Result.Reason); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetMatch loads the stored setup of a match
+func (r *PostgresRepository) GetMatch(ctx context.Context, matchID uuid.UUID) (*MatchRecord, error) {
+	record := MatchRecord{MatchID: matchID}
+	var (
+		clockTurnNo *int
+		deadline    *time.Time
+		pausedMs    *int64
+	)
+	query := `SELECT status, seed, rules, catalog_version, started_at, ended_at, winner_user_id, winner_team, end_reason,
+		is_private, host_user_id, spectating_disabled, queue, ranked, rated_at, clock_turn_no, turn_deadline, turn_paused_ms
+		FROM matches WHERE id = $1`
+	err := r.pool.QueryRow(ctx, query, matchID).Scan(
+		&record.Status, &record.Seed, &record.Rules, &record.CatalogVersion,
+		&record.StartedAt, &record.EndedAt, &record.WinnerUserID, &record.WinnerTeam, &record.EndReason,
+		&record.Private, &record.HostUserID, &record.SpectatingDisabled, &record.Queue, &record.Ranked, &record.RatedAt,
+		&clockTurnNo, &deadline, &pausedMs,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, err
+	}
+	if clockTurnNo != nil {
+		record.Clock = &TurnClock{TurnNo: *clockTurnNo, Deadline: deadline}
+		if pausedMs != nil {
+			paused := time.Duration(*pausedMs) * time.Millisecond
+			record.Clock.Paused = &paused
+		}
+	}
+
+	// Participants are returned in turn order so NewMatchState seats them the same way
+	query = `SELECT p.user_id, p.is_bot, p.starting_hp, p.starting_ap, p.start_x, p.start_y, p.team
+		FROM match_participants p JOIN matches m ON m.id = p.match_id
+		WHERE p.match_id = $1
+		ORDER BY array_position(m.turn_order, p.user_id), p.id`
+	rows, err := r.pool.Query(ctx, query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Participant
+		if err := rows.Scan(&p.UserID, &p.IsBot, &p.StartingHP, &p.StartingAP, &p.StartX, &p.StartY, &p.Team); err != nil {
+			return nil, err
+		}
+		record.Participants = append(record.Participants, p)
+	}
+
+	return &record, rows.Err()
+}
+
+// ListActiveMatchIDs returns every match that was running when the server last stopped
+func (r *PostgresRepository) ListActiveMatchIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM matches WHERE status = 'active' ORDER BY started_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListActions returns the action log of a match in the order it was applied
+func (r *PostgresRepository) ListActions(ctx context.Context, matchID uuid.UUID) ([]*ActionEntry, error) {
+	query := `SELECT id, match_id, seq, user_id, turn_no, action_type, payload, created_at
+		FROM match_actions WHERE match_id = $1 ORDER BY seq`
+	rows, err := r.pool.Query(ctx, query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*ActionEntry
+	for rows.Next() {
+		var e ActionEntry
+		if err := rows.Scan(&e.ID, &e.MatchID, &e.Seq, &e.UserID, &e.TurnNo, &e.ActionType, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListSnapshots returns every character and ability snapshot of a match, ordered by turn
+func (r *PostgresRepository) ListSnapshots(ctx context.Context, matchID uuid.UUID) ([]CharacterSnapshot, []AbilitySnapshot, error) {
+	query := `SELECT user_id, turn_no, hp, ap, x, y, effects
+		FROM character_snapshots WHERE match_id = $1 ORDER BY turn_no, user_id`
+	rows, err := r.pool.Query(ctx, query, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var characters []CharacterSnapshot
+	for rows.Next() {
+		var s CharacterSnapshot
+		if err := rows.Scan(&s.UserID, &s.TurnNo, &s.HP, &s.AP, &s.X, &s.Y, &s.Effects); err != nil {
+			return nil, nil, err
+		}
+		characters = append(characters, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	query = `SELECT user_id, turn_no, ability_id, uses_this_turn, per_target_uses
+		FROM ability_snapshots WHERE match_id = $1 ORDER BY turn_no, user_id, ability_id`
+	rows, err = r.pool.Query(ctx, query, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var abilities []AbilitySnapshot
+	for rows.Next() {
+		var s AbilitySnapshot
+		if err := rows.Scan(&s.UserID, &s.TurnNo, &s.AbilityID, &s.UsesThisTurn, &s.PerTargetUses); err != nil {
+			return nil, nil, err
+		}
+		abilities = append(abilities, s)
+	}
+
+	return characters, abilities, rows.Err()
+}
+
+// ListSnapshotsAt returns the character snapshots of the latest snapshotted
+// turn at or before turnNo
+func (r *PostgresRepository) ListSnapshotsAt(ctx context.Context, matchID uuid.UUID, turnNo int) ([]CharacterSnapshot, error) {
+	query := `SELECT user_id, turn_no, hp, ap, x, y, effects
+		FROM character_snapshots
+		WHERE match_id = $1 AND turn_no = (
+			SELECT MAX(turn_no) FROM character_snapshots WHERE match_id = $1 AND turn_no <= $2
+		)
+		ORDER BY user_id`
+	rows, err := r.pool.Query(ctx, query, matchID, turnNo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []CharacterSnapshot
+	for rows.Next() {
+		var s CharacterSnapshot
+		if err := rows.Scan(&s.UserID, &s.TurnNo, &s.HP, &s.AP, &s.X, &s.Y, &s.Effects); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// ImportMatch stores a match recorded elsewhere together with its action log
+// and the snapshots of every turn, failing with ErrMatchExists if the match
+// ID is already taken
+func (r *PostgresRepository) ImportMatch(ctx context.Context, record *MatchRecord, entries []*ActionEntry, snapshots []*MatchState) error {
+	rules, err := json.Marshal(record.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode rules: %w", err)
+	}
+	turnOrder := make([]uuid.UUID, 0, len(record.Participants))
+	for _, p := range record.Participants {
+		turnOrder = append(turnOrder, p.UserID)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO matches (id, status, started_at, ended_at, winner_user_id, winner_team, end_reason, seed, rules, turn_order, catalog_version,
+			is_private, host_user_id, spectating_disabled, queue, ranked, rated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (id) DO NOTHING`
+	tag, err := tx.Exec(ctx, query, record.MatchID, record.Status, record.StartedAt, record.EndedAt,
+		record.WinnerUserID, record.WinnerTeam, record.EndReason, record.Seed, rules, turnOrder, record.CatalogVersion,
+		record.Private, record.HostUserID, record.SpectatingDisabled, record.Queue, record.Ranked, record.RatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMatchExists
+	}
+
+	batch := &pgx.Batch{}
+	participantQuery := `INSERT INTO match_participants (match_id, user_id, is_bot, starting_hp, starting_ap, start_x, start_y, team) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	for _, p := range record.Participants {
+		batch.Queue(participantQuery, record.MatchID, p.UserID, p.IsBot, p.StartingHP, p.StartingAP, p.StartX, p.StartY, p.Team)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := appendAction(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	for _, state := range snapshots {
+		if err := saveSnapshots(ctx, tx, state); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetSpectating opens or closes a match to spectators
+func (r *PostgresRepository) SetSpectating(ctx context.Context, matchID uuid.UUID, disabled bool) error {
+	_, err := r.pool.Exec(ctx, `UPDATE matches SET spectating_disabled = $2 WHERE id = $1`, matchID, disabled)
+	return err
+}
+
+// SaveClock stores the timer of a match's current turn
+func (r *PostgresRepository) SaveClock(ctx context.Context, matchID uuid.UUID, clock TurnClock) error {
+	var pausedMs *int64
+	if clock.Paused != nil {
+		ms := clock.Paused.Milliseconds()
+		pausedMs = &ms
+	}
+	query := `UPDATE matches SET clock_turn_no = $2, turn_deadline = $3, turn_paused_ms = $4 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, matchID, clock.TurnNo, clock.Deadline, pausedMs)
+	return err
+}
+
+func appendAction(ctx context.Context, db execer, entry *ActionEntry) error {
+	payload, err := json.Marshal(entry.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode action payload: %w", err)
+	}
+
+	query := `INSERT INTO match_actions (id, match_id, seq, user_id, turn_no, action_type, payload, created_at, client_action_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))`
+	_, err = db.Exec(ctx, query, entry.ID, entry.MatchID, entry.Seq, entry.UserID, entry.TurnNo, entry.ActionType, payload, entry.CreatedAt, entry.Payload.ClientActionID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == clientActionConstraint {
+		return ErrDuplicateAction
+	}
+	return err
+}
+
+// saveSnapshots records every character, its status effects and its ability
+// usage as they stand at the start of the current turn
+func saveSnapshots(ctx context.Context, db execer, state *MatchState) error {
+	batch := &pgx.Batch{}
+	characterQuery := `INSERT INTO character_snapshots (match_id, user_id, turn_no, hp, ap, x, y, effects) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	abilityQuery := `INSERT INTO ability_snapshots (match_id, user_id, turn_no, ability_id, uses_this_turn, per_target_uses) VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, id := range state.TurnOrder {
+		c := state.Characters[id]
+		effects, err := json.Marshal(c.Effects)
+		if err != nil {
+			return fmt.Errorf("failed to encode status effects: %w", err)
+		}
+		if c.Effects == nil {
+			effects = []byte("[]")
+		}
+		batch.Queue(characterQuery, state.MatchID, c.UserID, state.TurnNo, c.HP, c.AP, c.X, c.Y, effects)
+
+		for _, abilityID := range sortedKeys(c.AbilityUsage) {
+			usage := c.AbilityUsage[abilityID]
+			perTarget, err := json.Marshal(usage.PerTargetUses)
+			if err != nil {
+				return fmt.Errorf("failed to encode per target uses: %w", err)
+			}
+			batch.Queue(abilityQuery, state.MatchID, c.UserID, state.TurnNo, abilityID, usage.UsesThisTurn, perTarget)
+		}
+	}
+
+	return db.SendBatch(ctx, batch).Close()
+}
+
+// sortedKeys keeps batch inserts in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
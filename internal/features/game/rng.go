@@ -0,0 +1,51 @@
+package game
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand/v2"
+)
+
+// Combat rolls, in percent
+const (
+	missChancePct     = 10
+	critChancePct     = 10
+	critMultiplierPct = 150
+	damageVariancePct = 20
+)
+
+// NewSeed returns a fresh random seed for a match
+func NewSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]) >> 1)
+}
+
+// random returns the match random source. The stream is keyed by the seed and
+// the turn number, so any turn can be replayed from its start without
+// replaying the turns before it.
+func (s *MatchState) random() *rand.Rand {
+	if s.pcg == nil {
+		s.pcg = rand.NewPCG(uint64(s.Seed), uint64(s.TurnNo))
+	}
+	return rand.New(s.pcg)
+}
+
+// rollDamage applies miss, critical and variance rolls to an ability's base damage
+func rollDamage(r *rand.Rand, base int) (damage int, missed, critical bool) {
+	if r.IntN(100) < missChancePct {
+		return 0, true, false
+	}
+
+	variance := r.IntN(2*damageVariancePct+1) - damageVariancePct
+	damage = base * (100 + variance) / 100
+
+	if r.IntN(100) < critChancePct {
+		critical = true
+		damage = damage * critMultiplierPct / 100
+	}
+
+	return damage, false, critical
+}
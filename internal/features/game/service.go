@@ -0,0 +1,331 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMatchNotFound       = errors.New("match not found")
+	ErrMatchAlreadyRunning = errors.New("match is already running")
+	ErrDuplicateAction     = errors.New("client action id is already logged for this match")
+	// ErrCatalogVersionMissing means a match cannot be replayed faithfully
+	// because the abilities it was played with are not stored
+	ErrCatalogVersionMissing = errors.New("ability catalog version of the match is not stored")
+)
+
+const (
+	// maxClientActionID is the longest client action id accepted
+	maxClientActionID = 64
+	// persistTimeout bounds database writes issued from turn timers
+	persistTimeout = 5 * time.Second
+	// commitRetryDelay is how long an expired turn waits before retrying a failed write
+	commitRetryDelay = time.Second
+)
+
+// Catalog supplies the ability definitions matches are played with. Every
+// version a match was started with stays available through SpecsAt.
+type Catalog interface {
+	Specs(ctx context.Context) (map[string]Ability, string, error)
+	SpecsAt(ctx context.Context, version string) (map[string]Ability, error)
+	SaveSpecs(ctx context.Context, version string, specs map[string]Ability) error
+}
+
+// Service runs live matches and drives their turn state machine
+type Service struct {
+	repo      Repository
+	catalog   Catalog
+	rules     Rules
+	events    Notifier
+	listeners []EndListener
+
+	mu      sync.RWMutex
+	matches map[uuid.UUID]*liveMatch
+}
+
+// liveMatch guards the state and turn timer of one running match
+type liveMatch struct {
+	mu       sync.Mutex
+	state    *MatchState
+	timer    *time.Timer
+	deadline time.Time
+
+	// seq is the number of actions written to the match log so far
+	seq int
+
+	// events holds the player events a reconnecting client resumes from,
+	// each numbered with the log seq of the last action before it
+	events []Event
+
+	// away tracks disconnected players; paused holds the time left on a turn
+	// whose clock stopped while its player is away
+	away   map[uuid.UUID]awayPlayer
+	paused *time.Duration
+
+	feed *spectatorFeed
+
+	// applied holds the result of every action sent with a client action id
+	applied map[actionKey]*ActionResult
+}
+
+// actionKey identifies an action by the id its client gave it
+type actionKey struct {
+	userID         uuid.UUID
+	clientActionID string
+}
+
+func newLiveMatch(state *MatchState) *liveMatch {
+	return &liveMatch{
+		state:   state,
+		away:    make(map[uuid.UUID]awayPlayer),
+		feed:    newSpectatorFeed(state),
+		applied: make(map[actionKey]*ActionResult),
+	}
+}
+
+// NewService creates a new game service using rules as the default match settings
+func NewService(repo Repository, catalog Catalog, rules Rules) *Service {
+	return &Service{
+		repo:    repo,
+		catalog: catalog,
+		rules:   rules,
+		events:  noopNotifier{},
+		matches: make(map[uuid.UUID]*liveMatch),
+	}
+}
+
+// SetNotifier attaches the transport used to push events to players
+func (s *Service) SetNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = n
+}
+
+// AddEndListener registers a listener for ended matches
+func (s *Service) AddEndListener(l EndListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// DefaultRules returns the settings applied to matches that do not override them
+func (s *Service) DefaultRules() Rules {
+	return s.rules
+}
+
+// Start registers a match, puts its players in its room and begins its first turn
+func (s *Service) Start(ctx context.Context, state *MatchState) error {
+	if state.Rules.IsZero() {
+		state.Rules = s.rules
+	}
+	if state.Seed == 0 {
+		state.Seed = NewSeed()
+	}
+	if state.CatalogVersion == "" {
+		_, version, err := s.catalog.Specs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load ability catalog: %w", err)
+		}
+		state.CatalogVersion = version
+	}
+
+	lm := newLiveMatch(state)
+
+	s.mu.Lock()
+	if _, exists := s.matches[state.MatchID]; exists {
+		s.mu.Unlock()
+		return ErrMatchAlreadyRunning
+	}
+	s.matches[state.MatchID] = lm
+	s.mu.Unlock()
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if err := s.repo.StartMatch(ctx, state); err != nil {
+		s.mu.Lock()
+		delete(s.matches, state.MatchID)
+		s.mu.Unlock()
+		return fmt.Errorf("failed to start match: %w", err)
+	}
+
+	s.notifier().JoinMatch(state.MatchID, AudiencePlayers, state.TurnOrder...)
+	s.beginTurn(lm)
+
+	slog.Info("Match started", "matchId", state.MatchID, "players", len(state.TurnOrder), "seed", state.Seed)
+	return nil
+}
+
+// Submit applies a player action to a running match. An action carrying a
+// client action id that was already applied is not applied again; the
+// result it had the first time is returned instead.
+func (s *Service) Submit(ctx context.Context, matchID uuid.UUID, action Action) (*ActionResult, error) {
+	if action.Type.IsSystem() {
+		return nil, ErrSystemAction
+	}
+	if len(action.ClientActionID) > maxClientActionID {
+		return nil, ErrInvalidActionID
+	}
+
+	lm, err := s.get(matchID)
+	if errors.Is(err, ErrMatchNotFound) && action.ClientActionID != "" {
+		// The retried action may be the one that ended the match
+		return s.loggedResult(ctx, matchID, action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if result, ok := lm.applied[actionKey{action.UserID, action.ClientActionID}]; ok {
+		return result, nil
+	}
+	return s.apply(ctx, lm, action)
+}
+
+// loggedResult looks up an action of a match that is no longer running by its
+// client action id and recomputes its result from the action log
+func (s *Service) loggedResult(ctx context.Context, matchID uuid.UUID, action Action) (*ActionResult, error) {
+	rebuilt, err := s.rebuild(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := actionKey{action.UserID, action.ClientActionID}
+	if result, ok := rebuilt.applied()[key]; ok {
+		return result, nil
+	}
+	return nil, ErrMatchNotFound
+}
+
+// State returns a copy of a running match and the deadline of its current turn
+func (s *Service) State(matchID uuid.UUID) (*MatchState, time.Time, error) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.state.Clone(), lm.deadline, nil
+}
+
+// Targets lists the tiles a player could aim an ability at from a given tile,
+// defaulting to the tile the player stands on
+func (s *Service) Targets(matchID, userID uuid.UUID, abilityID string, from *Position) ([]Position, error) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	caster, ok := lm.state.Characters[userID]
+	if !ok {
+		return nil, ErrNotParticipant
+	}
+	ability, ok := lm.state.Abilities[abilityID]
+	if !ok {
+		return nil, ErrUnknownAbility
+	}
+
+	origin := caster.Position()
+	if from != nil {
+		if !lm.state.Board.InBounds(*from) {
+			return nil, ErrOutOfBounds
+		}
+		origin = *from
+	}
+
+	return LegalTargets(lm.state, caster, ability, origin), nil
+}
+
+func (s *Service) get(matchID uuid.UUID) (*liveMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lm, ok := s.matches[matchID]
+	if !ok {
+		return nil, ErrMatchNotFound
+	}
+	return lm, nil
+}
+
+// apply resolves an action on a locked match, records it and moves the turn
+// state machine forward. The action is resolved on a copy of the state, which
+// only replaces the live state once the action log has been written, so a
+// failed write leaves the match exactly as it was.
+func (s *Service) apply(ctx context.Context, lm *liveMatch, action Action) (*ActionResult, error) {
+	turnNo := lm.state.TurnNo
+	state := lm.state.Clone()
+
+	result, err := ApplyAction(state, action)
+	if err != nil {
+		return nil, err
+	}
+
+	commit := &Commit{
+		Entry: &ActionEntry{
+			ID:         uuid.New(),
+			MatchID:    state.MatchID,
+			Seq:        lm.seq + 1,
+			UserID:     action.UserID,
+			TurnNo:     turnNo,
+			ActionType: action.Type,
+			Payload:    action,
+			CreatedAt:  time.Now(),
+		},
+	}
+	switch {
+	case state.Status == StatusEnded:
+		commit.Result = state.Result
+	case state.TurnNo != turnNo:
+		commit.Snapshot = state
+	}
+	if err := s.repo.Commit(ctx, commit); err != nil {
+		return nil, fmt.Errorf("failed to record action: %w", err)
+	}
+
+	lm.state = state
+	lm.seq++
+	if action.ClientActionID != "" {
+		lm.applied[actionKey{action.UserID, action.ClientActionID}] = result
+	}
+
+	msgType, data := actionEvent(state, action, turnNo, result)
+	s.notify(lm, msgType, data)
+
+	switch {
+	case state.Status == StatusEnded:
+		s.finish(lm)
+	case state.TurnNo != turnNo:
+		s.beginTurn(lm)
+	}
+
+	return result, nil
+}
+
+// actionEvent describes an applied action to the players: a timeout with the
+// standing of the player who ran out of time, anything else with its result
+func actionEvent(state *MatchState, action Action, turnNo int, result *ActionResult) (string, interface{}) {
+	if action.Type != ActionTimeout {
+		return EventActionApplied, ActionAppliedEvent{MatchID: state.MatchID, Result: result}
+	}
+	actor := state.Characters[action.UserID]
+	return EventTurnTimeout, TurnTimeoutEvent{
+		MatchID:             state.MatchID,
+		UserID:              action.UserID,
+		TurnNo:              turnNo,
+		ConsecutiveTimeouts: actor.ConsecutiveTimeouts,
+		Forfeited:           actor.Forfeited,
+	}
+}
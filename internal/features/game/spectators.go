@@ -0,0 +1,208 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// spectatorFeed relays match events to spectators once they are old enough
+// that watching a stream cannot give a player an edge. It is guarded by the
+// match lock.
+type spectatorFeed struct {
+	// viewers counts open spectator sessions per user
+	viewers map[uuid.UUID]int
+	// view is the state of the match as of the last released event
+	view    *MatchState
+	pending []delayedEvent
+	timer   *time.Timer
+}
+
+// delayedEvent is a match event waiting for the spectator delay to pass
+type delayedEvent struct {
+	turnNo int
+	due    time.Time
+	event  Event
+	state  *MatchState
+}
+
+func newSpectatorFeed(state *MatchState) *spectatorFeed {
+	return &spectatorFeed{
+		viewers: make(map[uuid.UUID]int),
+		view:    state.Clone(),
+	}
+}
+
+// Spectate adds a user as a read-only viewer of a running match and returns
+// the match as spectators currently see it. The first session of a user puts
+// them in the spectator room.
+func (s *Service) Spectate(matchID, userID uuid.UUID) (*MatchState, error) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if _, ok := lm.state.Characters[userID]; ok {
+		return nil, ErrAlreadyPlaying
+	}
+	if lm.state.SpectatingDisabled {
+		return nil, ErrSpectatingDisabled
+	}
+
+	lm.feed.viewers[userID]++
+	if lm.feed.viewers[userID] == 1 {
+		s.notifier().JoinMatch(matchID, AudienceSpectators, userID)
+		s.notifySpectatorCount(lm)
+	}
+
+	return lm.feed.view.Clone(), nil
+}
+
+// Unspectate removes one spectator session of a user; the last one takes them
+// out of the spectator room
+func (s *Service) Unspectate(matchID, userID uuid.UUID) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if lm.feed.viewers[userID] == 0 {
+		return
+	}
+	lm.feed.viewers[userID]--
+	if lm.feed.viewers[userID] == 0 {
+		delete(lm.feed.viewers, userID)
+		s.notifier().LeaveMatch(matchID, AudienceSpectators, userID)
+		s.notifySpectatorCount(lm)
+	}
+}
+
+// Spectators returns how many users are watching a running match
+func (s *Service) Spectators(matchID uuid.UUID) (int, error) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return 0, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return len(lm.feed.viewers), nil
+}
+
+// SetSpectating lets the host of a private match open or close it to
+// spectators; closing it removes everyone currently watching
+func (s *Service) SetSpectating(ctx context.Context, matchID, userID uuid.UUID, enabled bool) error {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	state := lm.state
+	if !state.Private {
+		return ErrNotPrivate
+	}
+	if state.HostUserID == nil || *state.HostUserID != userID {
+		return ErrNotHost
+	}
+	if state.SpectatingDisabled == !enabled {
+		return nil
+	}
+
+	if err := s.repo.SetSpectating(ctx, matchID, !enabled); err != nil {
+		return fmt.Errorf("failed to store spectating setting: %w", err)
+	}
+	state.SpectatingDisabled = !enabled
+
+	if !enabled && len(lm.feed.viewers) > 0 {
+		events := s.notifier()
+		events.NotifyMatch(matchID, AudienceSpectators, Event{
+			Type: EventSpectateClosed,
+			Data: SpectateClosedEvent{MatchID: matchID, Reason: "spectating_disabled"},
+		})
+		events.LeaveMatch(matchID, AudienceSpectators, lm.feed.viewerIDs()...)
+		lm.feed.viewers = make(map[uuid.UUID]int)
+		s.notifySpectatorCount(lm)
+	}
+
+	return nil
+}
+
+// relay queues an event for spectators and releases whatever is old enough.
+// The caller must hold the match lock.
+func (s *Service) relay(lm *liveMatch, event Event) {
+	lm.feed.pending = append(lm.feed.pending, delayedEvent{
+		turnNo: lm.state.TurnNo,
+		due:    time.Now().Add(lm.state.Rules.SpectatorDelay()),
+		event:  event,
+		state:  lm.state.Clone(),
+	})
+	s.release(lm)
+}
+
+// release sends spectators every queued event that is past both the turn and
+// the time delay, and schedules the next release. Once the match has ended
+// there is nothing left to protect and the queue is flushed. The caller must
+// hold the match lock.
+func (s *Service) release(lm *liveMatch) {
+	feed := lm.feed
+	if feed.timer != nil {
+		feed.timer.Stop()
+		feed.timer = nil
+	}
+
+	ended := lm.state.Status == StatusEnded
+	now := time.Now()
+	released := 0
+	for _, event := range feed.pending {
+		if !ended && (event.turnNo+lm.state.Rules.SpectatorDelayTurns > lm.state.TurnNo || now.Before(event.due)) {
+			break
+		}
+		if len(feed.viewers) > 0 {
+			s.notifier().NotifyMatch(lm.state.MatchID, AudienceSpectators, event.event)
+		}
+		feed.view = event.state
+		released++
+	}
+	feed.pending = feed.pending[released:]
+
+	// Events held back by time are released by a timer; those held back by
+	// turns wait for the next turn to start
+	if len(feed.pending) > 0 {
+		head := feed.pending[0]
+		if head.turnNo+lm.state.Rules.SpectatorDelayTurns <= lm.state.TurnNo {
+			feed.timer = time.AfterFunc(time.Until(head.due), func() {
+				lm.mu.Lock()
+				defer lm.mu.Unlock()
+				s.release(lm)
+			})
+		}
+	}
+}
+
+// notifySpectatorCount tells the players how many users are watching.
+// The caller must hold the match lock.
+func (s *Service) notifySpectatorCount(lm *liveMatch) {
+	s.notifier().NotifyMatch(lm.state.MatchID, AudiencePlayers, Event{
+		Type: EventSpectators,
+		Data: SpectatorsEvent{MatchID: lm.state.MatchID, Count: len(lm.feed.viewers)},
+	})
+}
+
+func (f *spectatorFeed) viewerIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(f.viewers))
+	for id := range f.viewers {
+		ids = append(ids, id)
+	}
+	return ids
+}
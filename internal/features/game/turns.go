@@ -0,0 +1,157 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// beginTurn arms the turn timer, announces the new turn and lets a bot whose
+// turn it is play it. Its snapshots were already written by the match start
+// or the action that began the turn. The caller must hold the match lock.
+func (s *Service) beginTurn(lm *liveMatch) {
+	state := lm.state
+	active := state.ActiveCharacter()
+
+	// Under the pause policy the clock of an away player only starts once they return
+	if _, away := lm.away[active.UserID]; away && state.Rules.TimerPolicy == TimerPolicyPause {
+		s.pauseTimer(lm, state.Rules.TurnTimeout())
+	} else {
+		s.armTimer(lm, state.Rules.TurnTimeout())
+	}
+
+	s.notify(lm, EventTurnStarted, TurnStartedEvent{
+		MatchID:      state.MatchID,
+		TurnNo:       state.TurnNo,
+		ActiveUserID: active.UserID,
+		AP:           active.AP,
+		Deadline:     lm.deadline,
+		Paused:       lm.paused != nil,
+	})
+
+	s.scheduleBot(lm)
+}
+
+// armTimer (re)starts the turn timer so the current turn ends after d.
+// The caller must hold the match lock.
+func (s *Service) armTimer(lm *liveMatch, d time.Duration) {
+	if lm.timer != nil {
+		lm.timer.Stop()
+	}
+
+	matchID, turnNo := lm.state.MatchID, lm.state.TurnNo
+	lm.paused = nil
+	lm.deadline = time.Now().Add(d)
+	lm.timer = time.AfterFunc(d, func() {
+		s.expireTurn(matchID, turnNo)
+	})
+	s.saveClock(lm)
+}
+
+// saveClock stores the timer of the current turn so a recovered match picks
+// it up where it stood. A failed write is only logged; recovery then falls
+// back to estimating the time left from the action log. The caller must hold
+// the match lock.
+func (s *Service) saveClock(lm *liveMatch) {
+	clock := TurnClock{TurnNo: lm.state.TurnNo}
+	if lm.paused != nil {
+		paused := *lm.paused
+		clock.Paused = &paused
+	} else {
+		deadline := lm.deadline
+		clock.Deadline = &deadline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+	if err := s.repo.SaveClock(ctx, lm.state.MatchID, clock); err != nil {
+		slog.Warn("Failed to store turn clock", "error", err, "matchId", lm.state.MatchID, "turnNo", clock.TurnNo)
+	}
+}
+
+// expireTurn ends a turn whose player ran out of time
+func (s *Service) expireTurn(matchID uuid.UUID, turnNo int) {
+	lm, err := s.get(matchID)
+	if err != nil {
+		return
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	// The player may have ended the turn just before the timer fired, or the
+	// clock may have been paused for a disconnected player
+	state := lm.state
+	if state.Status != StatusActive || state.TurnNo != turnNo || lm.paused != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	userID := state.ActiveUserID()
+	slog.Info("Turn timed out", "matchId", matchID, "turnNo", turnNo, "userId", userID)
+
+	if _, err := s.apply(ctx, lm, Action{Type: ActionTimeout, UserID: userID}); err != nil {
+		slog.Error("Failed to apply turn timeout", "error", err, "matchId", matchID, "turnNo", turnNo)
+		s.armTimer(lm, commitRetryDelay)
+	}
+}
+
+// finish stops the timer of an ended match, closes its rooms, unregisters it,
+// verifies its action log and tells the end listeners. Its result was written
+// with the action that ended it. The caller must hold the match lock.
+func (s *Service) finish(lm *liveMatch) {
+	state := lm.state
+	if lm.timer != nil {
+		lm.timer.Stop()
+	}
+	for _, player := range lm.away {
+		if player.timer != nil {
+			player.timer.Stop()
+		}
+	}
+
+	s.notify(lm, EventMatchEnded, MatchEndedEvent{
+		MatchID: state.MatchID,
+		Result:  state.Result,
+		Players: state.Summary(),
+		EndedAt: time.Now(),
+	})
+	// The final event flushed the spectator queue, so both rooms are done
+	s.notifier().CloseMatch(state.MatchID)
+
+	s.mu.Lock()
+	delete(s.matches, state.MatchID)
+	s.mu.Unlock()
+
+	slog.Info("Match ended", "matchId", state.MatchID, "reason", state.Result.Reason, "turnNo", state.Result.TurnNo)
+
+	go s.verify(state.MatchID)
+
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		go l.MatchEnded(state.Clone())
+	}
+}
+
+// notify numbers an event with the log seq of the last applied action, sends
+// it to the players' room and queues it for spectators. The caller must hold
+// the match lock.
+func (s *Service) notify(lm *liveMatch, msgType string, data interface{}) {
+	event := Event{Seq: lm.seq, Type: msgType, Data: data}
+	lm.events = append(lm.events, event)
+
+	s.notifier().NotifyMatch(lm.state.MatchID, AudiencePlayers, event)
+	s.relay(lm, event)
+}
+
+func (s *Service) notifier() Notifier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events
+}
@@ -0,0 +1,47 @@
+package game
+
+// AbilityUsage counts casts of one ability during its owner's turn, shaped
+// like ability_snapshots.uses_this_turn and per_target_uses
+type AbilityUsage struct {
+	UsesThisTurn  int            `json:"uses_this_turn"`
+	PerTargetUses map[string]int `json:"per_target_uses"`
+}
+
+// checkUsage rejects a cast that would exceed the ability's per turn limits
+func checkUsage(caster *Character, ability Ability, targets []*Character) error {
+	usage := caster.AbilityUsage[ability.ID]
+	if usage == nil {
+		return nil
+	}
+
+	if ability.PerTurnLimit != nil && usage.UsesThisTurn >= *ability.PerTurnLimit {
+		return ErrTurnLimitReached
+	}
+	if ability.PerTargetPerTurnLimit != nil {
+		for _, t := range targets {
+			if usage.PerTargetUses[t.UserID.String()] >= *ability.PerTargetPerTurnLimit {
+				return ErrTargetLimitReached
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordUsage counts a resolved cast against the caster's limits
+func recordUsage(caster *Character, ability Ability, targets []*Character) {
+	if caster.AbilityUsage == nil {
+		caster.AbilityUsage = make(map[string]*AbilityUsage)
+	}
+
+	usage := caster.AbilityUsage[ability.ID]
+	if usage == nil {
+		usage = &AbilityUsage{PerTargetUses: make(map[string]int)}
+		caster.AbilityUsage[ability.ID] = usage
+	}
+
+	usage.UsesThisTurn++
+	for _, t := range targets {
+		usage.PerTargetUses[t.UserID.String()]++
+	}
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAbilityUsageLimits(t *testing.T) {
+	limit := func(n int) *int { return &n }
+
+	// The caster stands between two opponents, both in range
+	caster, left, right := uuid.New(), uuid.New(), uuid.New()
+	leftTile, rightTile := Position{X: 0, Y: 1}, Position{X: 4, Y: 1}
+
+	// step casts at a tile, or with endRound lets every player end their turn
+	// until the caster's next turn
+	type step struct {
+		target   Position
+		endRound bool
+		wantErr  error
+	}
+
+	tests := []struct {
+		name    string
+		ability Ability
+		steps   []step
+	}{
+		{
+			name:    "no limits",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4},
+			steps: []step{
+				{target: rightTile},
+				{target: rightTile},
+				{target: rightTile},
+			},
+		},
+		{
+			name:    "per turn limit reached",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4, PerTurnLimit: limit(2)},
+			steps: []step{
+				{target: rightTile},
+				{target: leftTile},
+				{target: rightTile, wantErr: ErrTurnLimitReached},
+				{target: leftTile, wantErr: ErrTurnLimitReached},
+			},
+		},
+		{
+			name:    "per target limit allows a second target",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4, PerTargetPerTurnLimit: limit(1)},
+			steps: []step{
+				{target: rightTile},
+				{target: rightTile, wantErr: ErrTargetLimitReached},
+				{target: leftTile},
+				{target: leftTile, wantErr: ErrTargetLimitReached},
+			},
+		},
+		{
+			name:    "both limits",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4, PerTurnLimit: limit(3), PerTargetPerTurnLimit: limit(2)},
+			steps: []step{
+				{target: rightTile},
+				{target: rightTile},
+				{target: rightTile, wantErr: ErrTargetLimitReached},
+				{target: leftTile},
+				{target: leftTile, wantErr: ErrTurnLimitReached},
+			},
+		},
+		{
+			name: "area cast counts every character hit",
+			ability: Ability{
+				ID: "nova", BaseDamage: 1, APCost: 1, Range: 4,
+				AOERadius: limit(2), AOEShape: ShapeLine, PerTargetPerTurnLimit: limit(1),
+			},
+			steps: []step{
+				{target: Position{X: 3, Y: 1}},
+				{target: rightTile, wantErr: ErrTargetLimitReached},
+				{target: leftTile},
+			},
+		},
+		{
+			name:    "per turn limit resets on the next turn",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4, PerTurnLimit: limit(1)},
+			steps: []step{
+				{target: rightTile},
+				{target: rightTile, wantErr: ErrTurnLimitReached},
+				{endRound: true},
+				{target: rightTile},
+				{target: leftTile, wantErr: ErrTurnLimitReached},
+			},
+		},
+		{
+			name:    "per target limit resets on the next turn",
+			ability: Ability{ID: "jab", BaseDamage: 1, APCost: 1, Range: 4, PerTargetPerTurnLimit: limit(1)},
+			steps: []step{
+				{target: rightTile},
+				{target: rightTile, wantErr: ErrTargetLimitReached},
+				{endRound: true},
+				{target: rightTile},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := NewMatchState(uuid.New(), NewBoard(5, 3), map[string]Ability{tt.ability.ID: tt.ability}, []Participant{
+				{UserID: caster, StartingHP: 100, StartingAP: 10, StartX: 2, StartY: 1},
+				{UserID: left, StartingHP: 100, StartingAP: 10, StartX: leftTile.X, StartY: leftTile.Y},
+				{UserID: right, StartingHP: 100, StartingAP: 10, StartX: rightTile.X, StartY: rightTile.Y},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			state.Seed = 1
+
+			for i, s := range tt.steps {
+				if s.endRound {
+					for _, id := range state.TurnOrder {
+						if _, err := ApplyAction(state, Action{Type: ActionEndTurn, UserID: id}); err != nil {
+							t.Fatalf("step %d: end turn: %v", i+1, err)
+						}
+					}
+					continue
+				}
+
+				ap := state.Characters[caster].AP
+				_, err := ApplyAction(state, Action{Type: ActionCast, UserID: caster, AbilityID: tt.ability.ID, Target: s.target})
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: err = %v, want %v", i+1, err, s.wantErr)
+				}
+				if err != nil && state.Characters[caster].AP != ap {
+					t.Errorf("step %d: refused cast spent AP", i+1)
+				}
+			}
+		})
+	}
+}
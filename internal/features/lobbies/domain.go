@@ -0,0 +1,127 @@
+package lobbies
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxMembers is how many players a lobby seats
+	MaxMembers = 4
+	// codeLength is the number of characters in an invite code
+	codeLength = 6
+	// codeAlphabet leaves out characters that are easily misread
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// Bounds of the settings a host may pick
+const (
+	MinTurnTimeoutSec = 10
+	MaxTurnTimeoutSec = 300
+	MaxStartingHP     = 1000
+	MaxStartingAP     = 20
+)
+
+// Settings are chosen by the host and apply to the match the lobby starts
+type Settings struct {
+	Map            string `json:"map"`
+	TurnTimeoutSec int    `json:"turn_timeout_sec"`
+	StartingHP     int    `json:"starting_hp"`
+	StartingAP     int    `json:"starting_ap"`
+	// AllowedAbilities restricts the catalog; empty allows every ability
+	AllowedAbilities []string `json:"allowed_abilities,omitempty"`
+}
+
+// Member is a player waiting in a lobby
+type Member struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Ready    bool      `json:"ready"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Lobby is a private room players enter with its invite code. Its match
+// starts once at least two members are in and every one of them is ready.
+type Lobby struct {
+	Code       string     `json:"code"`
+	HostUserID uuid.UUID  `json:"host_user_id"`
+	Settings   Settings   `json:"settings"`
+	Members    []*Member  `json:"members"`
+	MatchID    *uuid.UUID `json:"match_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// starting is set while the match is being created; leaving holds the
+	// members who tried to leave meanwhile, taken out if the start fails
+	starting bool
+	leaving  []uuid.UUID
+}
+
+// Clone returns a copy that is safe to hand out while the lobby changes
+func (l *Lobby) Clone() *Lobby {
+	c := *l
+	c.Settings.AllowedAbilities = append([]string(nil), l.Settings.AllowedAbilities...)
+	c.Members = make([]*Member, len(l.Members))
+	for i, m := range l.Members {
+		mc := *m
+		c.Members[i] = &mc
+	}
+	return &c
+}
+
+// member returns a player's entry, or nil
+func (l *Lobby) member(userID uuid.UUID) *Member {
+	for _, m := range l.Members {
+		if m.UserID == userID {
+			return m
+		}
+	}
+	return nil
+}
+
+// userIDs lists the members in joining order
+func (l *Lobby) userIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(l.Members))
+	for i, m := range l.Members {
+		ids[i] = m.UserID
+	}
+	return ids
+}
+
+// allReady reports whether the lobby can start
+func (l *Lobby) allReady() bool {
+	if len(l.Members) < 2 {
+		return false
+	}
+	for _, m := range l.Members {
+		if !m.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// unready clears every member's ready flag, so they confirm again
+func (l *Lobby) unready() {
+	for _, m := range l.Members {
+		m.Ready = false
+	}
+}
+
+// Message types pushed to lobby members
+const (
+	EventLobbyUpdated = "lobby.updated"
+	EventLobbyStarted = "lobby.started"
+)
+
+var (
+	ErrLobbyNotFound   = errors.New("lobby not found")
+	ErrAlreadyInLobby  = errors.New("already in a lobby")
+	ErrNotInLobby      = errors.New("not in a lobby")
+	ErrLobbyFull       = errors.New("lobby is full")
+	ErrNotHost         = errors.New("only the host can change the settings")
+	ErrLobbyStarting   = errors.New("lobby match is starting")
+	ErrInMatch         = errors.New("already playing a match")
+	ErrInvalidSettings = errors.New("invalid lobby settings")
+	ErrUnknownAbility  = errors.New("unknown ability")
+)
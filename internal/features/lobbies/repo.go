@@ -0,0 +1,48 @@
+package lobbies
+
+import (
+	"context"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LobbyRepository interface for creating lobby matches
+type LobbyRepository interface {
+	CreateMatch(ctx context.Context, matchID uuid.UUID, participants []game.Participant) error
+}
+
+// PostgresLobbyRepository implements LobbyRepository
+type PostgresLobbyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL lobby repository
+func NewRepository(pool *pgxpool.Pool) LobbyRepository {
+	return &PostgresLobbyRepository{pool: pool}
+}
+
+// CreateMatch inserts a pending match and its participants in one transaction.
+// Lobby matches belong to no queue and are never rated.
+func (r *PostgresLobbyRepository) CreateMatch(ctx context.Context, matchID uuid.UUID, participants []game.Participant) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `INSERT INTO matches (id, status) VALUES ($1, 'pending')`, matchID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO match_participants (match_id, user_id, is_bot, starting_hp, starting_ap, start_x, start_y, team) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	for _, p := range participants {
+		if _, err := tx.Exec(ctx, query, matchID, p.UserID, p.IsBot, p.StartingHP, p.StartingAP, p.StartX, p.StartY, p.Team); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,441 @@
+package lobbies
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// Config holds the settings new lobbies start with
+type Config struct {
+	StartingHP int
+	StartingAP int
+}
+
+// Notifier pushes lobby messages to the room of a lobby, which members join
+// when they enter and leave when they go
+type Notifier interface {
+	JoinLobby(code string, userIDs ...uuid.UUID)
+	LeaveLobby(code string, userIDs ...uuid.UUID)
+	NotifyLobby(code string, event game.Event)
+	CloseLobby(code string)
+}
+
+type noopNotifier struct{}
+
+func (noopNotifier) JoinLobby(string, ...uuid.UUID)  {}
+func (noopNotifier) LeaveLobby(string, ...uuid.UUID) {}
+func (noopNotifier) NotifyLobby(string, game.Event)  {}
+func (noopNotifier) CloseLobby(string)               {}
+
+// LobbyStarted is sent to every member once the lobby's match is running
+type LobbyStarted struct {
+	Code    string    `json:"code"`
+	MatchID uuid.UUID `json:"match_id"`
+}
+
+// Service keeps the open lobbies in memory and starts their matches
+type Service struct {
+	repo    LobbyRepository
+	games   *game.Service
+	catalog game.Catalog
+	cfg     Config
+
+	mu      sync.Mutex
+	lobbies map[string]*Lobby
+	members map[uuid.UUID]*Lobby
+	events  Notifier
+}
+
+// NewService creates a new lobby service
+func NewService(repo LobbyRepository, games *game.Service, catalog game.Catalog, cfg Config) *Service {
+	return &Service{
+		repo:    repo,
+		games:   games,
+		catalog: catalog,
+		cfg:     cfg,
+		lobbies: make(map[string]*Lobby),
+		members: make(map[uuid.UUID]*Lobby),
+		events:  noopNotifier{},
+	}
+}
+
+// SetNotifier attaches the transport used to tell members about changes
+func (s *Service) SetNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = n
+}
+
+// Create opens a lobby hosted by the player, with the default settings
+func (s *Service) Create(userID uuid.UUID) (*Lobby, error) {
+	if s.games.InMatch(userID) {
+		return nil, ErrInMatch
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[userID]; ok {
+		return nil, ErrAlreadyInLobby
+	}
+	code, err := s.newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	l := &Lobby{
+		Code:       code,
+		HostUserID: userID,
+		Settings: Settings{
+			Map:            game.MapArena,
+			TurnTimeoutSec: s.games.DefaultRules().TurnTimeoutSec,
+			StartingHP:     s.cfg.StartingHP,
+			StartingAP:     s.cfg.StartingAP,
+		},
+		Members:   []*Member{{UserID: userID, JoinedAt: now}},
+		CreatedAt: now,
+	}
+	s.lobbies[code] = l
+	s.members[userID] = l
+	s.events.JoinLobby(code, userID)
+
+	slog.Debug("Lobby created", "code", code, "hostUserId", userID)
+	return l.Clone(), nil
+}
+
+// Join enters the lobby with the given invite code
+func (s *Service) Join(userID uuid.UUID, code string) (*Lobby, error) {
+	if s.games.InMatch(userID) {
+		return nil, ErrInMatch
+	}
+
+	s.mu.Lock()
+	if _, ok := s.members[userID]; ok {
+		s.mu.Unlock()
+		return nil, ErrAlreadyInLobby
+	}
+	l, ok := s.lobbies[strings.ToUpper(strings.TrimSpace(code))]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return nil, ErrLobbyNotFound
+	case l.starting:
+		s.mu.Unlock()
+		return nil, ErrLobbyStarting
+	case len(l.Members) >= MaxMembers:
+		s.mu.Unlock()
+		return nil, ErrLobbyFull
+	}
+
+	l.Members = append(l.Members, &Member{UserID: userID, JoinedAt: time.Now()})
+	s.members[userID] = l
+	s.events.JoinLobby(l.Code, userID)
+	snapshot := l.Clone()
+	s.mu.Unlock()
+
+	s.notify(snapshot)
+	return snapshot, nil
+}
+
+// Leave takes a player out of their lobby. The host's role passes to the
+// longest waiting member, and a lobby left empty is closed. While the match
+// is starting the player is already seated in it, so leaving is refused; they
+// are only taken out if the start fails.
+func (s *Service) Leave(userID uuid.UUID) error {
+	s.mu.Lock()
+	l, ok := s.members[userID]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return ErrNotInLobby
+	case l.starting:
+		l.leaving = append(l.leaving, userID)
+		s.mu.Unlock()
+		return ErrLobbyStarting
+	}
+
+	closed := s.remove(l, userID)
+	snapshot := l.Clone()
+	s.mu.Unlock()
+
+	if !closed {
+		s.notify(snapshot)
+	}
+	return nil
+}
+
+// remove takes a member out of a lobby, handing the host's role on or closing
+// the lobby when it is left empty, and reports whether it was closed. The
+// caller must hold the service lock.
+func (s *Service) remove(l *Lobby, userID uuid.UUID) bool {
+	delete(s.members, userID)
+	s.events.LeaveLobby(l.Code, userID)
+	for i, m := range l.Members {
+		if m.UserID == userID {
+			l.Members = append(l.Members[:i], l.Members[i+1:]...)
+			break
+		}
+	}
+
+	if len(l.Members) == 0 {
+		delete(s.lobbies, l.Code)
+		s.events.CloseLobby(l.Code)
+		slog.Debug("Lobby closed", "code", l.Code)
+		return true
+	}
+	if l.HostUserID == userID {
+		l.HostUserID = l.Members[0].UserID
+	}
+	return false
+}
+
+// Get returns the lobby a player is in
+func (s *Service) Get(userID uuid.UUID) (*Lobby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.members[userID]
+	if !ok {
+		return nil, ErrNotInLobby
+	}
+	return l.Clone(), nil
+}
+
+// UpdateSettings replaces the lobby settings; only the host may. Everyone has
+// to confirm they are ready again under the new settings.
+func (s *Service) UpdateSettings(ctx context.Context, userID uuid.UUID, settings Settings) (*Lobby, error) {
+	if err := s.validate(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	l, ok := s.members[userID]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return nil, ErrNotInLobby
+	case l.HostUserID != userID:
+		s.mu.Unlock()
+		return nil, ErrNotHost
+	case l.starting:
+		s.mu.Unlock()
+		return nil, ErrLobbyStarting
+	}
+
+	l.Settings = settings
+	l.unready()
+	snapshot := l.Clone()
+	s.mu.Unlock()
+
+	s.notify(snapshot)
+	return snapshot, nil
+}
+
+// SetReady marks a member ready or not. The match starts as soon as every
+// member of a lobby of two or more is ready.
+func (s *Service) SetReady(ctx context.Context, userID uuid.UUID, ready bool) (*Lobby, error) {
+	s.mu.Lock()
+	l, ok := s.members[userID]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return nil, ErrNotInLobby
+	case l.starting:
+		s.mu.Unlock()
+		return nil, ErrLobbyStarting
+	}
+
+	l.member(userID).Ready = ready
+	start := l.allReady()
+	l.starting = start
+	snapshot := l.Clone()
+	s.mu.Unlock()
+
+	s.notify(snapshot)
+	if !start {
+		return snapshot, nil
+	}
+
+	matchID, err := s.startMatch(ctx, snapshot)
+
+	s.mu.Lock()
+	l.starting = false
+	leaving := l.leaving
+	l.leaving = nil
+	if err != nil {
+		// Nobody is left waiting for a match that will not come, and those
+		// who tried to leave meanwhile are let go
+		l.unready()
+		closed := false
+		for _, id := range leaving {
+			if s.members[id] == l {
+				closed = s.remove(l, id)
+			}
+		}
+		snapshot = l.Clone()
+		s.mu.Unlock()
+
+		slog.Error("Failed to start lobby match", "error", err, "code", l.Code)
+		if !closed {
+			s.notify(snapshot)
+		}
+		return nil, err
+	}
+	l.MatchID = &matchID
+	delete(s.lobbies, l.Code)
+	for _, id := range l.userIDs() {
+		if s.members[id] == l {
+			delete(s.members, id)
+		}
+	}
+	// Sent before the lock is released so the room is closed before a new
+	// lobby can draw the same code
+	s.events.NotifyLobby(l.Code, game.Event{Type: EventLobbyStarted, Data: LobbyStarted{Code: l.Code, MatchID: matchID}})
+	s.events.CloseLobby(l.Code)
+	s.mu.Unlock()
+
+	snapshot.MatchID = &matchID
+	return snapshot, nil
+}
+
+// startMatch records and starts a private match for the members of a lobby,
+// hosted by its host and played under its settings
+func (s *Service) startMatch(ctx context.Context, l *Lobby) (uuid.UUID, error) {
+	for _, m := range l.Members {
+		if s.games.InMatch(m.UserID) {
+			return uuid.Nil, ErrInMatch
+		}
+	}
+
+	board, err := game.NewMap(l.Settings.Map)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	spawns := board.SpawnPoints(len(l.Members))
+	if len(spawns) < len(l.Members) {
+		return uuid.Nil, fmt.Errorf("board has room for %d players, need %d", len(spawns), len(l.Members))
+	}
+
+	participants := make([]game.Participant, len(l.Members))
+	for i, m := range l.Members {
+		participants[i] = game.Participant{
+			UserID:     m.UserID,
+			StartingHP: l.Settings.StartingHP,
+			StartingAP: l.Settings.StartingAP,
+			StartX:     spawns[i].X,
+			StartY:     spawns[i].Y,
+		}
+	}
+
+	abilities, version, err := s.catalog.Specs(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load ability catalog: %w", err)
+	}
+
+	rules := s.games.DefaultRules()
+	rules.TurnTimeoutSec = l.Settings.TurnTimeoutSec
+	rules.Map = l.Settings.Map
+	rules.AllowedAbilities = l.Settings.AllowedAbilities
+
+	matchID := uuid.New()
+	state, err := game.SetupMatch(matchID, rules, abilities, participants)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	state.CatalogVersion = version
+	host := l.HostUserID
+	state.Private = true
+	state.HostUserID = &host
+
+	if err := s.repo.CreateMatch(ctx, matchID, participants); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create match: %w", err)
+	}
+	if err := s.games.Start(ctx, state); err != nil {
+		return uuid.Nil, err
+	}
+
+	slog.Info("Lobby match started", "matchId", matchID, "code", l.Code, "players", len(participants))
+	return matchID, nil
+}
+
+// validate checks settings against their bounds and the catalog, filling in
+// the default map and dropping repeated abilities
+func (s *Service) validate(ctx context.Context, settings *Settings) error {
+	if settings.Map == "" {
+		settings.Map = game.MapArena
+	}
+	if _, err := game.NewMap(settings.Map); err != nil {
+		return fmt.Errorf("%w: unknown map %q", ErrInvalidSettings, settings.Map)
+	}
+	if settings.TurnTimeoutSec < MinTurnTimeoutSec || settings.TurnTimeoutSec > MaxTurnTimeoutSec {
+		return fmt.Errorf("%w: turn_timeout_sec must be between %d and %d", ErrInvalidSettings, MinTurnTimeoutSec, MaxTurnTimeoutSec)
+	}
+	if settings.StartingHP < 1 || settings.StartingHP > MaxStartingHP {
+		return fmt.Errorf("%w: starting_hp must be between 1 and %d", ErrInvalidSettings, MaxStartingHP)
+	}
+	if settings.StartingAP < 1 || settings.StartingAP > MaxStartingAP {
+		return fmt.Errorf("%w: starting_ap must be between 1 and %d", ErrInvalidSettings, MaxStartingAP)
+	}
+
+	if len(settings.AllowedAbilities) == 0 {
+		settings.AllowedAbilities = nil
+		return nil
+	}
+	abilities, _, err := s.catalog.Specs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load ability catalog: %w", err)
+	}
+	seen := make(map[string]bool, len(settings.AllowedAbilities))
+	allowed := make([]string, 0, len(settings.AllowedAbilities))
+	for _, id := range settings.AllowedAbilities {
+		if _, ok := abilities[id]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownAbility, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			allowed = append(allowed, id)
+		}
+	}
+	settings.AllowedAbilities = allowed
+	return nil
+}
+
+// newCode draws an invite code no open lobby uses. The caller must hold the lock.
+func (s *Service) newCode() (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for {
+		var b strings.Builder
+		for i := 0; i < codeLength; i++ {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("failed to draw invite code: %w", err)
+			}
+			b.WriteByte(codeAlphabet[n.Int64()])
+		}
+		if _, taken := s.lobbies[b.String()]; !taken {
+			return b.String(), nil
+		}
+	}
+}
+
+// notify tells every member of a lobby about its current state
+func (s *Service) notify(l *Lobby) {
+	s.notifier().NotifyLobby(l.Code, game.Event{Type: EventLobbyUpdated, Data: l})
+}
+
+func (s *Service) notifier() Notifier {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
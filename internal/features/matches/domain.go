@@ -0,0 +1,117 @@
+package matches
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match statuses, mirroring the matches.status check constraint
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+	StatusEnded   = "ended"
+)
+
+// Match domain model
+type Match struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	Status       string         `json:"status" db:"status"`
+	StartedAt    time.Time      `json:"started_at" db:"started_at"`
+	EndedAt      *time.Time     `json:"ended_at" db:"ended_at"`
+	WinnerUserID *uuid.UUID     `json:"winner_user_id" db:"winner_user_id"`
+	WinnerTeam   *int           `json:"winner_team" db:"winner_team"`
+	EndReason    *string        `json:"end_reason" db:"end_reason"`
+	Participants []*Participant `json:"participants"`
+}
+
+// HasParticipant reports whether the user plays in the match
+func (m *Match) HasParticipant(userID uuid.UUID) bool {
+	for _, p := range m.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Participant domain model
+type Participant struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MatchID    uuid.UUID `json:"match_id" db:"match_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	IsBot      bool      `json:"is_bot" db:"is_bot"`
+	StartingHP int       `json:"starting_hp" db:"starting_hp"`
+	StartingAP int       `json:"starting_ap" db:"starting_ap"`
+	StartX     int       `json:"start_x" db:"start_x"`
+	StartY     int       `json:"start_y" db:"start_y"`
+	Team       int       `json:"team" db:"team"`
+}
+
+// Cursor is a keyset position in a list ordered by started_at, then id, descending
+type Cursor struct {
+	StartedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque form handed to clients
+func (c Cursor) Encode() string {
+	raw := c.StartedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor previously returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	startedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if c.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ListFilter selects a page of a user's matches
+type ListFilter struct {
+	UserID uuid.UUID
+	Status string
+	After  *Cursor
+	Limit  int
+}
+
+// Page is a slice of matches and the cursor of the next slice, if any
+type Page struct {
+	Matches    []*Match
+	NextCursor string
+}
+
+// ValidateStatus checks a status filter value
+func ValidateStatus(status string) error {
+	switch status {
+	case "", StatusPending, StatusActive, StatusEnded:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+}
+
+var (
+	ErrMatchNotFound = errors.New("match not found")
+	ErrInvalidStatus = errors.New("invalid match status")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
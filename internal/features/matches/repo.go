@@ -0,0 +1,131 @@
+package matches
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MatchRepository interface for data access
+type MatchRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*Match, error)
+	ListByUser(ctx context.Context, filter ListFilter) ([]*Match, error)
+}
+
+// PostgresMatchRepository implements MatchRepository
+type PostgresMatchRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL match repository
+func NewRepository(pool *pgxpool.Pool) MatchRepository {
+	return &PostgresMatchRepository{pool: pool}
+}
+
+const matchColumns = `m.id, m.status, m.started_at, m.ended_at, m.winner_user_id, m.winner_team, m.end_reason`
+
+func (r *PostgresMatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches m WHERE m.id = $1`
+	match, err := scanMatch(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, err
+	}
+
+	if err := r.loadParticipants(ctx, []*Match{match}); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// ListByUser returns the user's matches newest first, after the filter cursor
+func (r *PostgresMatchRepository) ListByUser(ctx context.Context, filter ListFilter) ([]*Match, error) {
+	var (
+		afterStartedAt interface{}
+		afterID        interface{}
+		status         interface{}
+	)
+	if filter.After != nil {
+		afterStartedAt, afterID = filter.After.StartedAt, filter.After.ID
+	}
+	if filter.Status != "" {
+		status = filter.Status
+	}
+
+	// status uses idx_matches_status, the row comparison keeps pages stable
+	query := `SELECT ` + matchColumns + ` FROM matches m
+		WHERE EXISTS (SELECT 1 FROM match_participants p WHERE p.match_id = m.id AND p.user_id = $1)
+		AND ($2::text IS NULL OR m.status = $2)
+		AND ($3::timestamptz IS NULL OR (m.started_at, m.id) < ($3::timestamptz, $4::uuid))
+		ORDER BY m.started_at DESC, m.id DESC
+		LIMIT $5`
+	rows, err := r.pool.Query(ctx, query, filter.UserID, status, afterStartedAt, afterID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Match
+	for rows.Next() {
+		match, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadParticipants(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// loadParticipants fills the participants of the given matches with one query
+func (r *PostgresMatchRepository) loadParticipants(ctx context.Context, list []*Match) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*Match, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, m := range list {
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+
+	query := `SELECT id, match_id, user_id, is_bot, starting_hp, starting_ap, start_x, start_y, team
+		FROM match_participants WHERE match_id = ANY($1::uuid[]) ORDER BY match_id, id`
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Participant
+		if err := rows.Scan(&p.ID, &p.MatchID, &p.UserID, &p.IsBot, &p.StartingHP, &p.StartingAP, &p.StartX, &p.StartY, &p.Team); err != nil {
+			return err
+		}
+		m := byID[p.MatchID]
+		m.Participants = append(m.Participants, &p)
+	}
+
+	return rows.Err()
+}
+
+func scanMatch(row pgx.Row) (*Match, error) {
+	var m Match
+	err := row.Scan(&m.ID, &m.Status, &m.StartedAt, &m.EndedAt, &m.WinnerUserID, &m.WinnerTeam, &m.EndReason)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,79 @@
+package matches
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Service handles match read use cases
+type Service struct {
+	repo MatchRepository
+}
+
+// NewService creates a new match service
+func NewService(repo MatchRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetByID retrieves a match with its participants as seen by viewerID. Until
+// a match has ended only its players can read it; anyone else gets
+// ErrMatchNotFound so running matches cannot be looked up by id
+func (s *Service) GetByID(ctx context.Context, id, viewerID uuid.UUID) (*Match, error) {
+	match, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrMatchNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if match.Status != StatusEnded && !match.HasParticipant(viewerID) {
+		return nil, ErrMatchNotFound
+	}
+	return match, nil
+}
+
+// ListForUser returns one page of the matches a user took part in
+func (s *Service) ListForUser(ctx context.Context, userID uuid.UUID, status, cursor string, limit int) (*Page, error) {
+	if err := ValidateStatus(status); err != nil {
+		return nil, err
+	}
+
+	filter := ListFilter{UserID: userID, Status: status, Limit: limit}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// Fetch one extra row to know whether another page exists
+	wanted := filter.Limit
+	filter.Limit++
+	list, err := s.repo.ListByUser(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	page := &Page{Matches: list}
+	if len(list) > wanted {
+		page.Matches = list[:wanted]
+		last := page.Matches[wanted-1]
+		page.NextCursor = Cursor{StartedAt: last.StartedAt, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,210 @@
+package matchmaking
+
+import (
+	"errors"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// Queues players can join
+const (
+	QueueCasual      = "casual"
+	QueueRanked      = "ranked"
+	QueueTeams       = "teams"
+	QueueTeamsRanked = "teams_ranked"
+)
+
+// TeamSize is the number of players on each side in team queues
+const TeamSize = 2
+
+// queueConfig describes how a queue pairs players
+type queueConfig struct {
+	// ranked queues pair by rating and rate the matches they make
+	ranked bool
+	// teamSize is set for queues that seat two teams rather than two players
+	teamSize int
+}
+
+var queues = map[string]queueConfig{
+	QueueCasual:      {},
+	QueueRanked:      {ranked: true},
+	QueueTeams:       {teamSize: TeamSize},
+	QueueTeamsRanked: {ranked: true, teamSize: TeamSize},
+}
+
+// ticket is a player, or a party queueing together, waiting in a queue
+type ticket struct {
+	// userID is the player who queued; members lists everyone on the ticket,
+	// starting with them
+	userID   uuid.UUID
+	members  []uuid.UUID
+	queue    string
+	joinedAt time.Time
+	// rating is the mean rating of the members in a ranked queue
+	rating float64
+}
+
+// has reports whether a player is on the ticket
+func (t *ticket) has(userID uuid.UUID) bool {
+	for _, id := range t.members {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Status describes a player's place in the queue
+type Status struct {
+	Queued   bool       `json:"queued"`
+	Queue    string     `json:"queue,omitempty"`
+	JoinedAt *time.Time `json:"joined_at,omitempty"`
+	WaitSec  int        `json:"wait_sec"`
+	Position int        `json:"position,omitempty"`
+	Size     int        `json:"size"`
+	// BotAt is when the player is matched against a bot if nobody turns up
+	BotAt *time.Time `json:"bot_at,omitempty"`
+	// Rating and RatingWindow are set in ranked queues: opponents are within
+	// the window of the player's rating, which widens while they wait
+	Rating       *float64 `json:"rating,omitempty"`
+	RatingWindow *float64 `json:"rating_window,omitempty"`
+	// ProposedMatchID is the found match waiting for the player to accept
+	ProposedMatchID *uuid.UUID `json:"proposed_match_id,omitempty"`
+	// PartySize is the number of players queued together with the player
+	PartySize int `json:"party_size,omitempty"`
+	// CooldownUntil is when a player who declined may queue again
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// Seat is one participant of a found match
+type Seat struct {
+	UserID uuid.UUID `json:"user_id"`
+	IsBot  bool      `json:"is_bot"`
+	Team   int       `json:"team,omitempty"`
+}
+
+// MatchFound is sent to every player seated in a new match
+type MatchFound struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Queue   string    `json:"queue"`
+	Ranked  bool      `json:"ranked"`
+	Players []Seat    `json:"players"`
+}
+
+// MatchProposed is sent to every player of a found match, who each have to
+// accept it before ExpiresAt
+type MatchProposed struct {
+	MatchID          uuid.UUID `json:"match_id"`
+	Queue            string    `json:"queue"`
+	Ranked           bool      `json:"ranked"`
+	Players          []Seat    `json:"players"`
+	AcceptTimeoutSec int       `json:"accept_timeout_sec"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// ProposalAccepted tells the players of a found match who accepted so far
+type ProposalAccepted struct {
+	MatchID  uuid.UUID   `json:"match_id"`
+	Accepted []uuid.UUID `json:"accepted"`
+	Pending  int         `json:"pending"`
+}
+
+// Reasons a found match is called off
+const (
+	CancelDeclined = "declined"
+	CancelTimeout  = "timeout"
+	CancelFailed   = "failed"
+)
+
+// MatchCancelled tells a player that a found match will not start. Players
+// who accepted are back at the front of the queue; the others are on cooldown.
+type MatchCancelled struct {
+	MatchID       uuid.UUID  `json:"match_id"`
+	Reason        string     `json:"reason"`
+	Requeued      bool       `json:"requeued"`
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// proposal is a found match waiting for its players to accept
+type proposal struct {
+	group     group
+	state     *game.MatchState
+	ranked    bool
+	seats     []Seat
+	accepted  map[uuid.UUID]bool
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// humans lists the players who have to accept
+func (p *proposal) humans() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, t := range p.group.tickets() {
+		ids = append(ids, t.members...)
+	}
+	return ids
+}
+
+// progress describes who accepted so far
+func (p *proposal) progress() ProposalAccepted {
+	update := ProposalAccepted{MatchID: p.state.MatchID, Accepted: []uuid.UUID{}}
+	for _, id := range p.humans() {
+		if p.accepted[id] {
+			update.Accepted = append(update.Accepted, id)
+		} else {
+			update.Pending++
+		}
+	}
+	return update
+}
+
+// cooldownResetAfter is how long a player has to go without declining for
+// their cooldown to start over from the base
+const cooldownResetAfter = 24 * time.Hour
+
+// Cooldown keeps a player who declined a found match out of the queue
+type Cooldown struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Offences      int       `json:"offences"`
+	LastOffenceAt time.Time `json:"last_offence_at"`
+	Until         time.Time `json:"until"`
+}
+
+// nextCooldown returns a player's cooldown after another offence: the base
+// wait, doubled for every earlier offence within the reset period, up to max
+func nextCooldown(prev *Cooldown, userID uuid.UUID, now time.Time, base, max time.Duration) *Cooldown {
+	offences := 1
+	if prev != nil && now.Sub(prev.LastOffenceAt) < cooldownResetAfter {
+		offences = prev.Offences + 1
+	}
+
+	wait := base
+	for i := 1; i < offences && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return &Cooldown{UserID: userID, Offences: offences, LastOffenceAt: now, Until: now.Add(wait)}
+}
+
+// Message types pushed to players
+const (
+	EventMatchProposed  = "match.proposed"
+	EventMatchAccepted  = "match.accepted"
+	EventMatchCancelled = "match.cancelled"
+	EventMatchFound     = "match.found"
+)
+
+var (
+	ErrUnknownQueue  = errors.New("unknown queue")
+	ErrAlreadyQueued = errors.New("already in a queue")
+	ErrNotQueued     = errors.New("not in a queue")
+	ErrInMatch       = errors.New("already playing a match")
+	ErrOnCooldown    = errors.New("queueing is on cooldown after declining a match")
+	ErrNoProposal    = errors.New("no found match to answer")
+	ErrPartyTooLarge = errors.New("party is too large for this queue")
+)
@@ -0,0 +1,88 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNextCooldown(t *testing.T) {
+	const (
+		base = 30 * time.Second
+		max  = 5 * time.Minute
+	)
+	userID := uuid.New()
+	now := time.Date(2024, 8, 25, 12, 0, 0, 0, time.UTC)
+	earlier := func(offences int, ago time.Duration) *Cooldown {
+		return &Cooldown{UserID: userID, Offences: offences, LastOffenceAt: now.Add(-ago), Until: now.Add(-ago).Add(base)}
+	}
+
+	tests := []struct {
+		name         string
+		prev         *Cooldown
+		wantOffences int
+		wantWait     time.Duration
+	}{
+		{
+			name:         "first offence",
+			prev:         nil,
+			wantOffences: 1,
+			wantWait:     base,
+		},
+		{
+			name:         "second offence doubles",
+			prev:         earlier(1, time.Hour),
+			wantOffences: 2,
+			wantWait:     2 * base,
+		},
+		{
+			name:         "third offence doubles again",
+			prev:         earlier(2, time.Hour),
+			wantOffences: 3,
+			wantWait:     4 * base,
+		},
+		{
+			name:         "capped at the maximum",
+			prev:         earlier(4, time.Hour),
+			wantOffences: 5,
+			wantWait:     max,
+		},
+		{
+			name:         "stays capped after many offences",
+			prev:         earlier(100, time.Minute),
+			wantOffences: 101,
+			wantWait:     max,
+		},
+		{
+			name:         "starts over after the reset period",
+			prev:         earlier(4, cooldownResetAfter),
+			wantOffences: 1,
+			wantWait:     base,
+		},
+		{
+			name:         "counts up just before the reset period",
+			prev:         earlier(1, cooldownResetAfter-time.Second),
+			wantOffences: 2,
+			wantWait:     2 * base,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextCooldown(tt.prev, userID, now, base, max)
+			if got.UserID != userID {
+				t.Errorf("user = %s, want %s", got.UserID, userID)
+			}
+			if got.Offences != tt.wantOffences {
+				t.Errorf("offences = %d, want %d", got.Offences, tt.wantOffences)
+			}
+			if !got.LastOffenceAt.Equal(now) {
+				t.Errorf("last offence = %s, want %s", got.LastOffenceAt, now)
+			}
+			if wait := got.Until.Sub(now); wait != tt.wantWait {
+				t.Errorf("wait = %s, want %s", wait, tt.wantWait)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MatchmakingRepository interface for creating matched games
+type MatchmakingRepository interface {
+	CreateMatch(ctx context.Context, matchID uuid.UUID, queue string, ranked bool, participants []game.Participant) error
+	DeleteMatch(ctx context.Context, matchID uuid.UUID) error
+	DeletePendingMatches(ctx context.Context) (int64, error)
+	GetCooldown(ctx context.Context, userID uuid.UUID) (*Cooldown, error)
+	SaveCooldown(ctx context.Context, cooldown *Cooldown) error
+}
+
+// PostgresMatchmakingRepository implements MatchmakingRepository
+type PostgresMatchmakingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL matchmaking repository
+func NewRepository(pool *pgxpool.Pool) MatchmakingRepository {
+	return &PostgresMatchmakingRepository{pool: pool}
+}
+
+// CreateMatch inserts a pending match and its participants in one transaction
+func (r *PostgresMatchmakingRepository) CreateMatch(ctx context.Context, matchID uuid.UUID, queue string, ranked bool, participants []game.Participant) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `INSERT INTO matches (id, status, queue, ranked) VALUES ($1, 'pending', $2, $3)`, matchID, queue, ranked); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO match_participants (match_id, user_id, is_bot, starting_hp, starting_ap, start_x, start_y, team) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	for _, p := range participants {
+		if _, err := tx.Exec(ctx, query, matchID, p.UserID, p.IsBot, p.StartingHP, p.StartingAP, p.StartX, p.StartY, p.Team); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteMatch removes a match that never started, with its participants
+func (r *PostgresMatchmakingRepository) DeleteMatch(ctx context.Context, matchID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM matches WHERE id = $1 AND status = 'pending'`, matchID)
+	return err
+}
+
+// DeletePendingMatches removes every match that never started, with its
+// participants, and returns how many there were
+func (r *PostgresMatchmakingRepository) DeletePendingMatches(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM matches WHERE status = 'pending'`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetCooldown returns a player's queue cooldown, or nil if they never had one
+func (r *PostgresMatchmakingRepository) GetCooldown(ctx context.Context, userID uuid.UUID) (*Cooldown, error) {
+	c := Cooldown{UserID: userID}
+	query := `SELECT offences, last_offence_at, until FROM queue_cooldowns WHERE user_id = $1`
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&c.Offences, &c.LastOffenceAt, &c.Until)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveCooldown stores a player's queue cooldown
+func (r *PostgresMatchmakingRepository) SaveCooldown(ctx context.Context, c *Cooldown) error {
+	query := `INSERT INTO queue_cooldowns (user_id, offences, last_offence_at, until)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET offences = EXCLUDED.offences, last_offence_at = EXCLUDED.last_offence_at, until = EXCLUDED.until`
+	_, err := r.pool.Exec(ctx, query, c.UserID, c.Offences, c.LastOffenceAt, c.Until)
+	return err
+}
@@ -0,0 +1,840 @@
+package matchmaking
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/features/ratings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// passInterval is how often queued players are paired when nobody joins
+	passInterval = time.Second
+	// cancelTimeout bounds the cleanup after a found match is called off
+	cancelTimeout = 5 * time.Second
+)
+
+// Config controls how players are paired
+type Config struct {
+	// BotTimeout is how long a player waits before a bot is seated against
+	// them; zero disables bots
+	BotTimeout time.Duration
+	// StartingHP and StartingAP are given to every participant
+	StartingHP int
+	StartingAP int
+	// RatingWindow is the rating gap accepted between ranked opponents; it
+	// grows by RatingWindowGrowth per second waited, up to RatingWindowMax
+	RatingWindow       float64
+	RatingWindowGrowth float64
+	RatingWindowMax    float64
+	// AcceptTimeout is how long players have to accept a found match; zero
+	// starts found matches right away
+	AcceptTimeout time.Duration
+	// Cooldown is how long a player who declines or lets a found match lapse
+	// waits before queueing again. It doubles with every repeat offence, up
+	// to CooldownMax; zero disables cooldowns.
+	Cooldown    time.Duration
+	CooldownMax time.Duration
+}
+
+// Notifier pushes matchmaking messages to connected users
+type Notifier interface {
+	Notify(userIDs []uuid.UUID, event game.Event)
+}
+
+type noopNotifier struct{}
+
+func (noopNotifier) Notify([]uuid.UUID, game.Event) {}
+
+// Service queues players and starts a match once it can seat them
+type Service struct {
+	repo    MatchmakingRepository
+	games   *game.Service
+	catalog game.Catalog
+	ratings *ratings.Service
+	cfg     Config
+
+	mu     sync.Mutex
+	queue  []*ticket
+	events Notifier
+	// proposals holds the found matches waiting to be accepted, by player
+	proposals map[uuid.UUID]*proposal
+	// cooldowns caches when players on cooldown may queue again; ended
+	// cooldowns are pruned on every pass
+	cooldowns map[uuid.UUID]time.Time
+
+	// wake asks the running loop for an early pass
+	wake chan struct{}
+}
+
+// NewService creates a new matchmaking service
+func NewService(repo MatchmakingRepository, games *game.Service, catalog game.Catalog, ratings *ratings.Service, cfg Config) *Service {
+	return &Service{
+		repo:      repo,
+		games:     games,
+		catalog:   catalog,
+		ratings:   ratings,
+		cfg:       cfg,
+		events:    noopNotifier{},
+		proposals: make(map[uuid.UUID]*proposal),
+		cooldowns: make(map[uuid.UUID]time.Time),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetNotifier attaches the transport used to tell players about found matches
+func (s *Service) SetNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = n
+}
+
+// Join puts a player in a queue; an empty queue name means casual
+func (s *Service) Join(ctx context.Context, userID uuid.UUID, queue string) (*Status, error) {
+	return s.JoinGroup(ctx, []uuid.UUID{userID}, queue)
+}
+
+// JoinGroup puts players in a queue together, as one party that is seated on
+// the same team. The first player is the one who queued them. In ranked
+// queues the party is matched on the mean of its members' ratings.
+func (s *Service) JoinGroup(ctx context.Context, userIDs []uuid.UUID, queue string) (*Status, error) {
+	if queue == "" {
+		queue = QueueCasual
+	}
+	config, ok := queues[queue]
+	if !ok {
+		return nil, ErrUnknownQueue
+	}
+	if len(userIDs) > max(1, config.teamSize) {
+		return nil, ErrPartyTooLarge
+	}
+	for _, id := range userIDs {
+		if s.games.InMatch(id) {
+			return nil, ErrInMatch
+		}
+		if err := s.loadCooldown(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &ticket{userID: userIDs[0], members: append([]uuid.UUID(nil), userIDs...), queue: queue}
+	if config.ranked {
+		for _, id := range userIDs {
+			rating, err := s.ratings.Get(ctx, id, queue)
+			if err != nil {
+				return nil, err
+			}
+			t.rating += rating.Rating
+		}
+		t.rating /= float64(len(userIDs))
+	}
+
+	// Checked under the lock so that a concurrent join, proposal or cooldown
+	// cannot slip in between the check and the queueing
+	s.mu.Lock()
+	now := time.Now()
+	for _, id := range userIDs {
+		var err error
+		switch _, proposed := s.proposals[id]; {
+		case now.Before(s.cooldowns[id]):
+			err = ErrOnCooldown
+		case proposed || s.find(id) >= 0:
+			err = ErrAlreadyQueued
+		}
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	t.joinedAt = now
+	s.queue = append(s.queue, t)
+	status := s.status(t.userID, now)
+	s.mu.Unlock()
+
+	slog.Debug("Player queued", "userId", t.userID, "queue", queue, "partySize", len(userIDs))
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return status, nil
+}
+
+// Leave takes a player out of the queue, together with their party.
+// Leaving while a found match waits for them declines it.
+func (s *Service) Leave(userID uuid.UUID) error {
+	s.mu.Lock()
+	if p, ok := s.proposals[userID]; ok {
+		s.mu.Unlock()
+		s.cancel(p, []uuid.UUID{userID}, CancelDeclined)
+		return nil
+	}
+	defer s.mu.Unlock()
+
+	i := s.find(userID)
+	if i < 0 {
+		return ErrNotQueued
+	}
+	s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	return nil
+}
+
+// Accept confirms a player will play a found match. The match starts once
+// every player accepted.
+func (s *Service) Accept(ctx context.Context, userID, matchID uuid.UUID) error {
+	s.mu.Lock()
+	p, ok := s.proposals[userID]
+	if !ok || p.state.MatchID != matchID {
+		s.mu.Unlock()
+		return ErrNoProposal
+	}
+	p.accepted[userID] = true
+	update := p.progress()
+	ready := update.Pending == 0
+	if ready {
+		p.timer.Stop()
+		s.dropProposal(p)
+	}
+	s.mu.Unlock()
+
+	s.notifier().Notify(p.humans(), game.Event{Type: EventMatchAccepted, Data: update})
+	if !ready {
+		return nil
+	}
+
+	if err := s.beginMatch(ctx, p); err != nil {
+		slog.Error("Failed to start accepted match", "error", err, "matchId", matchID)
+		s.callOff(p, nil, CancelFailed)
+	}
+	return nil
+}
+
+// Decline refuses a found match; it is called off and the player is put on
+// cooldown
+func (s *Service) Decline(userID, matchID uuid.UUID) error {
+	s.mu.Lock()
+	p, ok := s.proposals[userID]
+	s.mu.Unlock()
+	if !ok || p.state.MatchID != matchID {
+		return ErrNoProposal
+	}
+
+	s.cancel(p, []uuid.UUID{userID}, CancelDeclined)
+	return nil
+}
+
+// Status returns a player's place in the queue
+func (s *Service) Status(userID uuid.UUID) *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status(userID, time.Now())
+}
+
+// DiscardPending removes the pending matches left behind by the last
+// shutdown. Proposals and lobby starts live only in memory, so at startup no
+// pending match can still begin; call it before accepting players.
+func (s *Service) DiscardPending(ctx context.Context) (int64, error) {
+	return s.repo.DeletePendingMatches(ctx)
+}
+
+// Run pairs queued players on every interval, and right after someone joins,
+// until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(passInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.matchmake(ctx, time.Now())
+	}
+}
+
+// side is a player, or a team in team queues, padded with bots
+type side struct {
+	tickets []*ticket
+	bots    int
+}
+
+// players counts the seats of a side
+func (sd side) players() int {
+	n := sd.bots
+	for _, t := range sd.tickets {
+		n += len(t.members)
+	}
+	return n
+}
+
+// group is the sides to seat in one match
+type group struct {
+	queue string
+	sides []side
+}
+
+// tickets lists every ticket seated in the group
+func (g group) tickets() []*ticket {
+	var tickets []*ticket
+	for _, sd := range g.sides {
+		tickets = append(tickets, sd.tickets...)
+	}
+	return tickets
+}
+
+// bots counts the bots seated in the group
+func (g group) bots() int {
+	n := 0
+	for _, sd := range g.sides {
+		n += sd.bots
+	}
+	return n
+}
+
+// matchmake takes every group that can be seated out of the queue and
+// starts their matches. Players whose match fails to start are queued again
+// at their old place.
+func (s *Service) matchmake(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	groups := s.pair(now)
+	s.pruneCooldowns(now)
+	s.mu.Unlock()
+
+	for _, g := range groups {
+		if err := s.proposeMatch(ctx, g); err != nil {
+			slog.Error("Failed to start matched game", "error", err, "queue", g.queue)
+			s.requeue(g.tickets())
+		}
+	}
+}
+
+// pair removes and returns the groups that can be seated now, and players
+// who waited past the bot timeout against a bot. Players are considered in
+// the order they joined; in casual queues they meet the next player in line,
+// in ranked queues the closest rated player within both their windows. Team
+// queues are filled by teams.
+// The caller must hold the lock.
+func (s *Service) pair(now time.Time) []group {
+	var (
+		groups []group
+		rest   []*ticket
+		taken  = make(map[*ticket]bool)
+	)
+	for i, t := range s.queue {
+		if taken[t] {
+			continue
+		}
+		if queues[t.queue].teamSize > 0 {
+			if g, ok := s.formTeams(t, s.queue[i+1:], taken, now); ok {
+				groups = append(groups, g)
+			}
+			continue
+		}
+
+		var match *ticket
+		for _, other := range s.queue[i+1:] {
+			if taken[other] || other.queue != t.queue {
+				continue
+			}
+			if !queues[t.queue].ranked {
+				match = other
+				break
+			}
+			gap := math.Abs(t.rating - other.rating)
+			if gap > math.Min(s.window(t, now), s.window(other, now)) {
+				continue
+			}
+			if match == nil || gap < math.Abs(t.rating-match.rating) {
+				match = other
+			}
+		}
+
+		switch {
+		case match != nil:
+			taken[t], taken[match] = true, true
+			groups = append(groups, group{queue: t.queue, sides: []side{{tickets: []*ticket{t}}, {tickets: []*ticket{match}}}})
+		case s.cfg.BotTimeout > 0 && now.Sub(t.joinedAt) >= s.cfg.BotTimeout:
+			taken[t] = true
+			groups = append(groups, group{queue: t.queue, sides: []side{{tickets: []*ticket{t}}, {bots: 1}}})
+		}
+	}
+
+	for _, t := range s.queue {
+		if !taken[t] {
+			rest = append(rest, t)
+		}
+	}
+	s.queue = rest
+	return groups
+}
+
+// formTeams seats the first ticket and the next ones in line on two full
+// teams, splitting them so that the team ratings are as close as possible.
+// In ranked queues only tickets within both ratings windows of the first are
+// considered. Once the first ticket waited past the bot timeout, teams that
+// cannot be filled are padded with bots. Seated tickets are marked taken.
+// The caller must hold the lock.
+func (s *Service) formTeams(t *ticket, rest []*ticket, taken map[*ticket]bool, now time.Time) (group, bool) {
+	config := queues[t.queue]
+	seats := 2 * config.teamSize
+
+	pool, players := []*ticket{t}, len(t.members)
+	for _, other := range rest {
+		if players == seats {
+			break
+		}
+		if taken[other] || other.queue != t.queue || players+len(other.members) > seats {
+			continue
+		}
+		if config.ranked && math.Abs(t.rating-other.rating) > math.Min(s.window(t, now), s.window(other, now)) {
+			continue
+		}
+		pool = append(pool, other)
+		players += len(other.members)
+	}
+
+	padded := s.cfg.BotTimeout > 0 && now.Sub(t.joinedAt) >= s.cfg.BotTimeout
+	if players < seats && !padded {
+		return group{}, false
+	}
+
+	// The first ticket always plays on the first team; every split of the
+	// rest is tried
+	var (
+		best  []side
+		score = math.Inf(1)
+	)
+	for mask := 0; mask < 1<<(len(pool)-1); mask++ {
+		sides := []side{{tickets: []*ticket{t}}, {}}
+		for i, other := range pool[1:] {
+			team := mask >> i & 1
+			sides[team].tickets = append(sides[team].tickets, other)
+		}
+		if sides[0].players() > config.teamSize || sides[1].players() > config.teamSize {
+			continue
+		}
+		gap := 0.0
+		if config.ranked {
+			gap = math.Abs(teamRating(sides[0]) - teamRating(sides[1]))
+		}
+		if gap < score {
+			best, score = sides, gap
+		}
+	}
+	if best == nil {
+		return group{}, false
+	}
+
+	for i := range best {
+		best[i].bots = config.teamSize - best[i].players()
+		for _, member := range best[i].tickets {
+			taken[member] = true
+		}
+	}
+	return group{queue: t.queue, sides: best}, true
+}
+
+// teamRating is the mean rating of a team's players; an empty team counts
+// as zero
+func teamRating(sd side) float64 {
+	total, players := 0.0, 0
+	for _, t := range sd.tickets {
+		total += t.rating * float64(len(t.members))
+		players += len(t.members)
+	}
+	if players == 0 {
+		return 0
+	}
+	return total / float64(players)
+}
+
+// window returns the rating gap a ranked player accepts after waiting
+func (s *Service) window(t *ticket, now time.Time) float64 {
+	window := s.cfg.RatingWindow + s.cfg.RatingWindowGrowth*now.Sub(t.joinedAt).Seconds()
+	return math.Min(window, s.cfg.RatingWindowMax)
+}
+
+// requeueFront puts tickets back at the very front of the queue, in the
+// order given
+func (s *Service) requeueFront(tickets []*ticket) {
+	if len(tickets) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(append([]*ticket(nil), tickets...), s.queue...)
+}
+
+// requeue puts tickets back in the queue in joining order
+func (s *Service) requeue(tickets []*ticket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tickets {
+		i := 0
+		for i < len(s.queue) && !s.queue[i].joinedAt.After(t.joinedAt) {
+			i++
+		}
+		s.queue = append(s.queue[:i], append([]*ticket{t}, s.queue[i:]...)...)
+	}
+}
+
+// proposeMatch records a pending match for a group and asks its players to
+// accept it; without an accept timeout the match starts right away
+func (s *Service) proposeMatch(ctx context.Context, g group) error {
+	seats := seatSides(g.sides, queues[g.queue].teamSize > 0)
+
+	board, err := game.NewMap(game.MapArena)
+	if err != nil {
+		return err
+	}
+	spawns := board.SpawnPoints(len(seats))
+	if len(spawns) < len(seats) {
+		return fmt.Errorf("board has room for %d players, need %d", len(spawns), len(seats))
+	}
+
+	participants := make([]game.Participant, len(seats))
+	for i, seat := range seats {
+		participants[i] = game.Participant{
+			UserID:     seat.UserID,
+			IsBot:      seat.IsBot,
+			StartingHP: s.cfg.StartingHP,
+			StartingAP: s.cfg.StartingAP,
+			StartX:     spawns[i].X,
+			StartY:     spawns[i].Y,
+			Team:       seat.Team,
+		}
+	}
+
+	abilities, version, err := s.catalog.Specs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load ability catalog: %w", err)
+	}
+
+	// Games against bots are never rated
+	ranked := queues[g.queue].ranked && g.bots() == 0
+
+	matchID := uuid.New()
+	state, err := game.SetupMatch(matchID, s.games.DefaultRules(), abilities, participants)
+	if err != nil {
+		return err
+	}
+	state.CatalogVersion = version
+	if err := s.repo.CreateMatch(ctx, matchID, g.queue, ranked, participants); err != nil {
+		return fmt.Errorf("failed to create match: %w", err)
+	}
+
+	p := &proposal{group: g, state: state, ranked: ranked, seats: seats, accepted: make(map[uuid.UUID]bool)}
+	if s.cfg.AcceptTimeout <= 0 {
+		if err := s.beginMatch(ctx, p); err != nil {
+			s.repo.DeleteMatch(ctx, matchID)
+			return err
+		}
+		return nil
+	}
+
+	p.expiresAt = time.Now().Add(s.cfg.AcceptTimeout)
+	s.mu.Lock()
+	for _, id := range p.humans() {
+		s.proposals[id] = p
+	}
+	p.timer = time.AfterFunc(s.cfg.AcceptTimeout, func() { s.expire(p) })
+	s.mu.Unlock()
+
+	s.notifier().Notify(p.humans(), game.Event{Type: EventMatchProposed, Data: MatchProposed{
+		MatchID:          matchID,
+		Queue:            g.queue,
+		Ranked:           ranked,
+		Players:          seats,
+		AcceptTimeoutSec: int(s.cfg.AcceptTimeout.Seconds()),
+		ExpiresAt:        p.expiresAt,
+	}})
+
+	slog.Info("Match proposed", "matchId", matchID, "queue", g.queue, "players", len(p.humans()), "bots", g.bots())
+	return nil
+}
+
+// beginMatch starts a found match and tells its players where to go
+func (s *Service) beginMatch(ctx context.Context, p *proposal) error {
+	if err := s.games.Start(ctx, p.state); err != nil {
+		return err
+	}
+
+	matchID := p.state.MatchID
+	s.notifier().Notify(p.humans(), game.Event{Type: EventMatchFound, Data: MatchFound{MatchID: matchID, Queue: p.group.queue, Ranked: p.ranked, Players: p.seats}})
+
+	slog.Info("Match found", "matchId", matchID, "queue", p.group.queue, "players", len(p.humans()), "bots", p.group.bots())
+	return nil
+}
+
+// expire calls off a found match that was not accepted in time; everyone who
+// did not accept is put on cooldown
+func (s *Service) expire(p *proposal) {
+	var late []uuid.UUID
+	s.mu.Lock()
+	for _, id := range p.humans() {
+		if !p.accepted[id] {
+			late = append(late, id)
+		}
+	}
+	s.mu.Unlock()
+
+	s.cancel(p, late, CancelTimeout)
+}
+
+// cancel calls off a found match that is still waiting to be accepted
+func (s *Service) cancel(p *proposal, offenders []uuid.UUID, reason string) {
+	s.mu.Lock()
+	if s.proposals[p.humans()[0]] != p {
+		// Already started or called off
+		s.mu.Unlock()
+		return
+	}
+	p.timer.Stop()
+	s.dropProposal(p)
+	s.mu.Unlock()
+
+	s.callOff(p, offenders, reason)
+}
+
+// callOff discards the pending match of a proposal that will not start. The
+// offenders are put on cooldown. Tickets whose members all accepted go back to
+// the front of the queue and the other tickets without an offender go back at
+// their old place.
+func (s *Service) callOff(p *proposal, offenders []uuid.UUID, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	matchID := p.state.MatchID
+	if err := s.repo.DeleteMatch(ctx, matchID); err != nil {
+		slog.Error("Failed to delete called off match", "error", err, "matchId", matchID)
+	}
+
+	penalized := make(map[uuid.UUID]*time.Time, len(offenders))
+	for _, id := range offenders {
+		penalized[id] = nil
+		if s.cfg.Cooldown <= 0 {
+			continue
+		}
+		until, err := s.penalize(ctx, id)
+		if err != nil {
+			slog.Error("Failed to store queue cooldown", "error", err, "userId", id)
+			continue
+		}
+		penalized[id] = &until
+	}
+
+	var front, back []*ticket
+	requeued := make(map[uuid.UUID]bool)
+	s.mu.Lock()
+	for _, t := range p.group.tickets() {
+		clean, accepted := true, true
+		for _, id := range t.members {
+			if _, ok := penalized[id]; ok {
+				clean = false
+			}
+			if !p.accepted[id] {
+				accepted = false
+			}
+		}
+		if !clean {
+			continue
+		}
+		if accepted {
+			front = append(front, t)
+		} else {
+			back = append(back, t)
+		}
+		for _, id := range t.members {
+			requeued[id] = true
+		}
+	}
+	s.mu.Unlock()
+	// Back first: requeue relies on the queue being in joining order
+	s.requeue(back)
+	s.requeueFront(front)
+
+	for _, id := range p.humans() {
+		s.notifier().Notify([]uuid.UUID{id}, game.Event{Type: EventMatchCancelled, Data: MatchCancelled{
+			MatchID:       matchID,
+			Reason:        reason,
+			Requeued:      requeued[id],
+			CooldownUntil: penalized[id],
+		}})
+	}
+
+	slog.Info("Match called off", "matchId", matchID, "reason", reason, "offenders", len(offenders), "requeued", len(front)+len(back))
+
+	if len(front)+len(back) > 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// dropProposal forgets a proposal. The caller must hold the lock.
+func (s *Service) dropProposal(p *proposal) {
+	for _, id := range p.humans() {
+		if s.proposals[id] == p {
+			delete(s.proposals, id)
+		}
+	}
+}
+
+// penalize extends a player's queue cooldown after declining and returns
+// when it ends
+func (s *Service) penalize(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	prev, err := s.repo.GetCooldown(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	c := nextCooldown(prev, userID, time.Now(), s.cfg.Cooldown, s.cfg.CooldownMax)
+	if err := s.repo.SaveCooldown(ctx, c); err != nil {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	s.cooldowns[userID] = c.Until
+	s.mu.Unlock()
+
+	slog.Info("Queue cooldown", "userId", userID, "offences", c.Offences, "until", c.Until)
+	return c.Until, nil
+}
+
+// loadCooldown caches a player's stored queue cooldown if it has not ended
+// yet. A cooldown cached in the meantime by penalize is never shortened.
+func (s *Service) loadCooldown(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	_, ok := s.cooldowns[userID]
+	s.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	c, err := s.repo.GetCooldown(ctx, userID)
+	if err != nil || c == nil || !time.Now().Before(c.Until) {
+		return err
+	}
+
+	s.mu.Lock()
+	if c.Until.After(s.cooldowns[userID]) {
+		s.cooldowns[userID] = c.Until
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// pruneCooldowns forgets the cooldowns that have ended. The caller must hold
+// the lock.
+func (s *Service) pruneCooldowns(now time.Time) {
+	for id, until := range s.cooldowns {
+		if !now.Before(until) {
+			delete(s.cooldowns, id)
+		}
+	}
+}
+
+// find returns the queue index of a player's ticket, or -1. The caller must
+// hold the lock.
+func (s *Service) find(userID uuid.UUID) int {
+	for i, t := range s.queue {
+		if t.has(userID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// seatSides lists the seats of a match. Players of each side are seated in
+// turn, so team members alternate with their opponents in the turn order and
+// spawn on the same edge of the board.
+func seatSides(sides []side, teams bool) []Seat {
+	lists := make([][]Seat, len(sides))
+	for i, sd := range sides {
+		team := 0
+		if teams {
+			team = i + 1
+		}
+		for _, t := range sd.tickets {
+			for _, id := range t.members {
+				lists[i] = append(lists[i], Seat{UserID: id, Team: team})
+			}
+		}
+		for b := 0; b < sd.bots; b++ {
+			lists[i] = append(lists[i], Seat{UserID: uuid.New(), IsBot: true, Team: team})
+		}
+	}
+
+	var seats []Seat
+	for i := 0; ; i++ {
+		added := false
+		for _, list := range lists {
+			if i < len(list) {
+				seats = append(seats, list[i])
+				added = true
+			}
+		}
+		if !added {
+			return seats
+		}
+	}
+}
+
+// status describes a player's place in their queue. The caller must hold the lock.
+func (s *Service) status(userID uuid.UUID, now time.Time) *Status {
+	i := s.find(userID)
+	if i < 0 {
+		status := &Status{}
+		if p, ok := s.proposals[userID]; ok {
+			status.ProposedMatchID = &p.state.MatchID
+		}
+		if until, ok := s.cooldowns[userID]; ok && now.Before(until) {
+			status.CooldownUntil = &until
+		}
+		return status
+	}
+
+	t := s.queue[i]
+	status := &Status{
+		Queued:    true,
+		Queue:     t.queue,
+		JoinedAt:  &t.joinedAt,
+		PartySize: len(t.members),
+		WaitSec:   int(now.Sub(t.joinedAt).Seconds()),
+	}
+	for _, other := range s.queue {
+		if other.queue != t.queue {
+			continue
+		}
+		status.Size++
+		if other == t {
+			status.Position = status.Size
+		}
+	}
+	if s.cfg.BotTimeout > 0 {
+		botAt := t.joinedAt.Add(s.cfg.BotTimeout)
+		status.BotAt = &botAt
+	}
+	if queues[t.queue].ranked {
+		rating, window := t.rating, s.window(t, now)
+		status.Rating, status.RatingWindow = &rating, &window
+	}
+	return status
+}
+
+func (s *Service) notifier() Notifier {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
@@ -0,0 +1,143 @@
+package matchmaking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// memRepo keeps cooldowns in memory and ignores match writes
+type memRepo struct {
+	cooldowns map[uuid.UUID]*Cooldown
+}
+
+func (r *memRepo) CreateMatch(context.Context, uuid.UUID, string, bool, []game.Participant) error {
+	return nil
+}
+
+func (r *memRepo) DeleteMatch(context.Context, uuid.UUID) error { return nil }
+
+func (r *memRepo) DeletePendingMatches(context.Context) (int64, error) { return 0, nil }
+
+func (r *memRepo) GetCooldown(_ context.Context, userID uuid.UUID) (*Cooldown, error) {
+	return r.cooldowns[userID], nil
+}
+
+func (r *memRepo) SaveCooldown(_ context.Context, c *Cooldown) error {
+	r.cooldowns[c.UserID] = c
+	return nil
+}
+
+func TestCallOffRequeues(t *testing.T) {
+	t0 := time.Date(2024, 8, 25, 12, 0, 0, 0, time.UTC)
+	solo := func(name string, joined time.Duration) *ticket {
+		id := uuid.New()
+		return &ticket{userID: id, members: []uuid.UUID{id}, queue: name, joinedAt: t0.Add(joined)}
+	}
+	party := func(name string, joined time.Duration) *ticket {
+		leader, member := uuid.New(), uuid.New()
+		return &ticket{userID: leader, members: []uuid.UUID{leader, member}, queue: name, joinedAt: t0.Add(joined)}
+	}
+
+	tests := []struct {
+		name string
+		// seated are the tickets of the proposal, waiting the rest of the queue
+		seated  []*ticket
+		waiting []*ticket
+		// accepted and offenders index members of the seated tickets as
+		// [ticket, member]
+		accepted  [][2]int
+		offenders [][2]int
+		reason    string
+		// want lists the queue afterwards by ticket queue name
+		want []string
+	}{
+		{
+			name:      "accepted go first, unanswered keep their place",
+			seated:    []*ticket{solo("accepted", 0), solo("unanswered", 2*time.Second), solo("declined", -time.Second)},
+			waiting:   []*ticket{solo("w1", time.Second), solo("w2", 3*time.Second)},
+			accepted:  [][2]int{{0, 0}},
+			offenders: [][2]int{{2, 0}},
+			reason:    CancelDeclined,
+			want:      []string{"accepted", "w1", "unanswered", "w2"},
+		},
+		{
+			name:      "unanswered ahead of everyone waiting",
+			seated:    []*ticket{solo("unanswered", -time.Second), solo("declined", 0)},
+			waiting:   []*ticket{solo("w1", time.Second)},
+			offenders: [][2]int{{1, 0}},
+			reason:    CancelDeclined,
+			want:      []string{"unanswered", "w1"},
+		},
+		{
+			name:      "timeout requeues only those who accepted",
+			seated:    []*ticket{solo("accepted", time.Second), solo("late", 0)},
+			waiting:   []*ticket{solo("w1", -time.Second)},
+			accepted:  [][2]int{{0, 0}},
+			offenders: [][2]int{{1, 0}},
+			reason:    CancelTimeout,
+			want:      []string{"accepted", "w1"},
+		},
+		{
+			name:      "party goes first only when every member accepted",
+			seated:    []*ticket{party("half", 2*time.Second), party("full", 3*time.Second), solo("declined", 0)},
+			waiting:   []*ticket{solo("w1", time.Second), solo("w2", 4*time.Second)},
+			accepted:  [][2]int{{0, 0}, {1, 0}, {1, 1}},
+			offenders: [][2]int{{2, 0}},
+			reason:    CancelDeclined,
+			want:      []string{"full", "w1", "half", "w2"},
+		},
+		{
+			name:     "failed start puts everyone first",
+			seated:   []*ticket{solo("a", 2*time.Second), solo("b", 3*time.Second)},
+			waiting:  []*ticket{solo("w1", time.Second)},
+			accepted: [][2]int{{0, 0}, {1, 0}},
+			reason:   CancelFailed,
+			want:     []string{"a", "b", "w1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(&memRepo{cooldowns: make(map[uuid.UUID]*Cooldown)}, nil, nil, nil, Config{Cooldown: time.Minute, CooldownMax: time.Hour})
+			s.queue = append([]*ticket(nil), tt.waiting...)
+
+			p := &proposal{
+				group:    group{sides: []side{{tickets: tt.seated}}},
+				state:    &game.MatchState{MatchID: uuid.New()},
+				accepted: make(map[uuid.UUID]bool),
+			}
+			for _, at := range tt.accepted {
+				p.accepted[tt.seated[at[0]].members[at[1]]] = true
+			}
+			var offenders []uuid.UUID
+			for _, at := range tt.offenders {
+				offenders = append(offenders, tt.seated[at[0]].members[at[1]])
+			}
+
+			s.callOff(p, offenders, tt.reason)
+
+			var got []string
+			for _, q := range s.queue {
+				got = append(got, q.queue)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("queue = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("queue = %v, want %v", got, tt.want)
+				}
+			}
+			for _, id := range offenders {
+				if _, ok := s.cooldowns[id]; !ok {
+					t.Errorf("offender %s has no cooldown", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package parties
+
+import (
+	"errors"
+	"time"
+
+	"demondoof-backend/internal/features/matchmaking"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxMembers is the size of a full party, one team in team queues
+	MaxMembers = matchmaking.TeamSize
+	// inviteTTL is how long an invite can be accepted
+	inviteTTL = 5 * time.Minute
+)
+
+// Party is a group of players who queue together under their leader. It is
+// stored, so it outlives the connections of its members.
+type Party struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	LeaderUserID uuid.UUID `json:"leader_user_id" db:"leader_user_id"`
+	Members      []*Member `json:"members"`
+	Invites      []*Invite `json:"invites"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Member is a player in a party
+type Member struct {
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+	// Online is whether the member has an open connection
+	Online bool `json:"online"`
+}
+
+// Invite is a pending invitation to join a party
+type Invite struct {
+	PartyID   uuid.UUID `json:"party_id" db:"party_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	InvitedBy uuid.UUID `json:"invited_by" db:"invited_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// State is what a player sees of parties: the one they are in, if any, and
+// the invites waiting for them
+type State struct {
+	Party   *Party    `json:"party"`
+	Invites []*Invite `json:"invites"`
+}
+
+// userIDs lists the members in joining order, which starts with the founder
+func (p *Party) userIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(p.Members))
+	for i, m := range p.Members {
+		ids[i] = m.UserID
+	}
+	return ids
+}
+
+// queueOrder lists the members with the leader first, as they are queued
+func (p *Party) queueOrder() []uuid.UUID {
+	ids := []uuid.UUID{p.LeaderUserID}
+	for _, m := range p.Members {
+		if m.UserID != p.LeaderUserID {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+// Message types pushed to players
+const (
+	EventPartyUpdated = "party.updated"
+	EventPartyInvited = "party.invited"
+	EventPartyQueued  = "party.queued"
+)
+
+var (
+	ErrNotInParty     = errors.New("not in a party")
+	ErrAlreadyInParty = errors.New("already in a party")
+	ErrNotLeader      = errors.New("only the party leader can do this")
+	ErrPartyFull      = errors.New("party is full")
+	ErrInviteNotFound = errors.New("invite not found or expired")
+	ErrInviteSelf     = errors.New("cannot invite yourself")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrMembersOffline = errors.New("every party member must be online to queue")
+)
@@ -0,0 +1,257 @@
+package parties
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PartyRepository interface for data access
+type PartyRepository interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*Party, error)
+	Create(ctx context.Context, leaderID uuid.UUID) (*Party, error)
+	Invite(ctx context.Context, invite *Invite) error
+	GetInvite(ctx context.Context, partyID, userID uuid.UUID) (*Invite, error)
+	ListInvites(ctx context.Context, userID uuid.UUID) ([]*Invite, error)
+	DeleteInvite(ctx context.Context, partyID, userID uuid.UUID) error
+	AddMember(ctx context.Context, partyID, userID uuid.UUID, maxMembers int) error
+	RemoveMember(ctx context.Context, userID uuid.UUID) error
+}
+
+// PostgresPartyRepository implements PartyRepository
+type PostgresPartyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL party repository
+func NewRepository(pool *pgxpool.Pool) PartyRepository {
+	return &PostgresPartyRepository{pool: pool}
+}
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// GetByUser returns the party a player is in, with its members and the
+// invites that have not expired
+func (r *PostgresPartyRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*Party, error) {
+	var p Party
+	query := `SELECT p.id, p.leader_user_id, p.created_at
+		FROM parties p JOIN party_members m ON m.party_id = p.id
+		WHERE m.user_id = $1`
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&p.ID, &p.LeaderUserID, &p.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotInParty
+		}
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT user_id, joined_at FROM party_members WHERE party_id = $1 ORDER BY joined_at, user_id`, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.JoinedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.Members = append(p.Members, &m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	p.Invites, err = r.listInvites(ctx, `WHERE party_id = $1 AND expires_at > NOW()`, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Create starts a party with the player as leader and only member
+func (r *PostgresPartyRepository) Create(ctx context.Context, leaderID uuid.UUID) (*Party, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	p := Party{LeaderUserID: leaderID, Invites: []*Invite{}}
+	query := `INSERT INTO parties (leader_user_id) VALUES ($1) RETURNING id, created_at`
+	if err := tx.QueryRow(ctx, query, leaderID).Scan(&p.ID, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	m := Member{UserID: leaderID}
+	query = `INSERT INTO party_members (user_id, party_id) VALUES ($1, $2) RETURNING joined_at`
+	if err := tx.QueryRow(ctx, query, leaderID, p.ID).Scan(&m.JoinedAt); err != nil {
+		if isViolation(err, uniqueViolation) {
+			return nil, ErrAlreadyInParty
+		}
+		return nil, err
+	}
+	p.Members = []*Member{&m}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Invite stores an invite, renewing an earlier one to the same party
+func (r *PostgresPartyRepository) Invite(ctx context.Context, invite *Invite) error {
+	query := `INSERT INTO party_invites (party_id, user_id, invited_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (party_id, user_id) DO UPDATE
+		SET invited_by = EXCLUDED.invited_by, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at`
+	_, err := r.pool.Exec(ctx, query, invite.PartyID, invite.UserID, invite.InvitedBy, invite.CreatedAt, invite.ExpiresAt)
+	if isViolation(err, foreignKeyViolation) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// GetInvite returns an invite that has not expired
+func (r *PostgresPartyRepository) GetInvite(ctx context.Context, partyID, userID uuid.UUID) (*Invite, error) {
+	invites, err := r.listInvites(ctx, `WHERE party_id = $1 AND user_id = $2 AND expires_at > NOW()`, partyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(invites) == 0 {
+		return nil, ErrInviteNotFound
+	}
+	return invites[0], nil
+}
+
+// ListInvites returns the invites waiting for a player, newest first
+func (r *PostgresPartyRepository) ListInvites(ctx context.Context, userID uuid.UUID) ([]*Invite, error) {
+	return r.listInvites(ctx, `WHERE user_id = $1 AND expires_at > NOW()`, userID)
+}
+
+func (r *PostgresPartyRepository) listInvites(ctx context.Context, where string, args ...any) ([]*Invite, error) {
+	query := `SELECT party_id, user_id, invited_by, created_at, expires_at FROM party_invites ` + where + ` ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*Invite{}
+	for rows.Next() {
+		var i Invite
+		if err := rows.Scan(&i.PartyID, &i.UserID, &i.InvitedBy, &i.CreatedAt, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &i)
+	}
+	return invites, rows.Err()
+}
+
+// DeleteInvite removes an invite
+func (r *PostgresPartyRepository) DeleteInvite(ctx context.Context, partyID, userID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM party_invites WHERE party_id = $1 AND user_id = $2`, partyID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// AddMember moves an invited player into a party and uses up their invite.
+// The party row is locked so two players cannot take its last place.
+func (r *PostgresPartyRepository) AddMember(ctx context.Context, partyID, userID uuid.UUID, maxMembers int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM parties WHERE id = $1 FOR UPDATE`, partyID); err != nil {
+		return err
+	}
+	var members int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM party_members WHERE party_id = $1`, partyID).Scan(&members); err != nil {
+		return err
+	}
+	if members == 0 {
+		return ErrInviteNotFound
+	}
+	if members >= maxMembers {
+		return ErrPartyFull
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM party_invites WHERE party_id = $1 AND user_id = $2 AND expires_at > $3`, partyID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInviteNotFound
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO party_members (user_id, party_id) VALUES ($1, $2)`, userID, partyID); err != nil {
+		if isViolation(err, uniqueViolation) {
+			return ErrAlreadyInParty
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveMember takes a player out of their party. The longest standing member
+// takes over as leader when the leader leaves, and a party left empty is
+// deleted.
+func (r *PostgresPartyRepository) RemoveMember(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var partyID, leaderID uuid.UUID
+	query := `SELECT p.id, p.leader_user_id
+		FROM parties p JOIN party_members m ON m.party_id = p.id
+		WHERE m.user_id = $1
+		FOR UPDATE OF p`
+	if err := tx.QueryRow(ctx, query, userID).Scan(&partyID, &leaderID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotInParty
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM party_members WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	var next uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT user_id FROM party_members WHERE party_id = $1 ORDER BY joined_at, user_id LIMIT 1`, partyID).Scan(&next)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if _, err := tx.Exec(ctx, `DELETE FROM parties WHERE id = $1`, partyID); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case leaderID == userID:
+		if _, err := tx.Exec(ctx, `UPDATE parties SET leader_user_id = $2 WHERE id = $1`, partyID, next); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func isViolation(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,247 @@
+package parties
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/features/matchmaking"
+
+	"github.com/google/uuid"
+)
+
+// Notifier pushes party messages to connected users and tells who is online
+type Notifier interface {
+	Notify(userIDs []uuid.UUID, event game.Event)
+	Online(userID uuid.UUID) bool
+}
+
+type noopNotifier struct{}
+
+func (noopNotifier) Notify([]uuid.UUID, game.Event) {}
+func (noopNotifier) Online(uuid.UUID) bool          { return false }
+
+// PartyQueued tells the members of a party that their leader queued them
+type PartyQueued struct {
+	PartyID uuid.UUID           `json:"party_id"`
+	Status  *matchmaking.Status `json:"status"`
+}
+
+// Service manages parties and queues them for matchmaking
+type Service struct {
+	repo        PartyRepository
+	matchmaking *matchmaking.Service
+
+	mu     sync.RWMutex
+	events Notifier
+}
+
+// NewService creates a new party service
+func NewService(repo PartyRepository, matchmaking *matchmaking.Service) *Service {
+	return &Service{
+		repo:        repo,
+		matchmaking: matchmaking,
+		events:      noopNotifier{},
+	}
+}
+
+// SetNotifier attaches the transport used to reach party members
+func (s *Service) SetNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = n
+}
+
+// Get returns the party a player is in, if any, and their pending invites
+func (s *Service) Get(ctx context.Context, userID uuid.UUID) (*State, error) {
+	party, err := s.party(ctx, userID)
+	if err != nil && !errors.Is(err, ErrNotInParty) {
+		return nil, err
+	}
+	invites, err := s.repo.ListInvites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &State{Party: party, Invites: invites}, nil
+}
+
+// Invite asks a player to join the leader's party. A player who is not in a
+// party yet founds one by inviting.
+func (s *Service) Invite(ctx context.Context, leaderID, userID uuid.UUID) (*Party, error) {
+	if leaderID == userID {
+		return nil, ErrInviteSelf
+	}
+
+	// Checked first so a refused invite does not found a party
+	if _, err := s.repo.GetByUser(ctx, userID); err == nil {
+		return nil, ErrAlreadyInParty
+	} else if !errors.Is(err, ErrNotInParty) {
+		return nil, err
+	}
+
+	party, err := s.repo.GetByUser(ctx, leaderID)
+	if errors.Is(err, ErrNotInParty) {
+		party, err = s.repo.Create(ctx, leaderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if party.LeaderUserID != leaderID {
+		return nil, ErrNotLeader
+	}
+	if len(party.Members) >= MaxMembers {
+		return nil, ErrPartyFull
+	}
+
+	now := time.Now()
+	invite := &Invite{PartyID: party.ID, UserID: userID, InvitedBy: leaderID, CreatedAt: now, ExpiresAt: now.Add(inviteTTL)}
+	if err := s.repo.Invite(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	s.notifier().Notify([]uuid.UUID{userID}, game.Event{Type: EventPartyInvited, Data: invite})
+	slog.Debug("Party invite sent", "partyId", party.ID, "userId", userID, "invitedBy", leaderID)
+	return s.changed(ctx, leaderID)
+}
+
+// Accept joins the party a player was invited to. A queued party is taken
+// out of the queue, since it is no longer the party that queued.
+func (s *Service) Accept(ctx context.Context, userID, partyID uuid.UUID) (*Party, error) {
+	if err := s.repo.AddMember(ctx, partyID, userID, MaxMembers); err != nil {
+		return nil, err
+	}
+
+	party, err := s.changed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.matchmaking.Leave(userID)
+	s.matchmaking.Leave(party.LeaderUserID)
+
+	slog.Debug("Party joined", "partyId", partyID, "userId", userID)
+	return party, nil
+}
+
+// Decline turns down an invite
+func (s *Service) Decline(ctx context.Context, userID, partyID uuid.UUID) error {
+	invite, err := s.repo.GetInvite(ctx, partyID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteInvite(ctx, partyID, userID); err != nil {
+		return err
+	}
+
+	s.changed(ctx, invite.InvitedBy)
+	return nil
+}
+
+// Leave takes a player out of their party, and the party out of the queue
+func (s *Service) Leave(ctx context.Context, userID uuid.UUID) error {
+	party, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.matchmaking.Leave(userID)
+
+	if err := s.repo.RemoveMember(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, m := range party.Members {
+		if m.UserID != userID {
+			s.changed(ctx, m.UserID)
+			break
+		}
+	}
+	slog.Debug("Party left", "partyId", party.ID, "userId", userID)
+	return nil
+}
+
+// Queue puts the leader's party in a matchmaking queue as one ticket. Every
+// member must be online to answer the match found for them.
+func (s *Service) Queue(ctx context.Context, leaderID uuid.UUID, queue string) (*matchmaking.Status, error) {
+	party, err := s.party(ctx, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	if party.LeaderUserID != leaderID {
+		return nil, ErrNotLeader
+	}
+	for _, m := range party.Members {
+		if !m.Online {
+			return nil, ErrMembersOffline
+		}
+	}
+
+	status, err := s.matchmaking.JoinGroup(ctx, party.queueOrder(), queue)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier().Notify(party.userIDs(), game.Event{Type: EventPartyQueued, Data: PartyQueued{PartyID: party.ID, Status: status}})
+	return status, nil
+}
+
+// LeaveQueue takes a player's party out of the queue; any member may
+func (s *Service) LeaveQueue(ctx context.Context, userID uuid.UUID) (*matchmaking.Status, error) {
+	party, err := s.party(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.matchmaking.Leave(userID); err != nil {
+		return nil, err
+	}
+
+	status := s.matchmaking.Status(userID)
+	s.notifier().Notify(party.userIDs(), game.Event{Type: EventPartyQueued, Data: PartyQueued{PartyID: party.ID, Status: status}})
+	return status, nil
+}
+
+// Connected and Disconnected tell a player's party that they came online or
+// went offline. The party itself is kept either way.
+func (s *Service) Connected(ctx context.Context, userID uuid.UUID) {
+	s.presenceChanged(ctx, userID)
+}
+
+func (s *Service) Disconnected(ctx context.Context, userID uuid.UUID) {
+	s.presenceChanged(ctx, userID)
+}
+
+func (s *Service) presenceChanged(ctx context.Context, userID uuid.UUID) {
+	if _, err := s.changed(ctx, userID); err != nil && !errors.Is(err, ErrNotInParty) {
+		slog.Warn("Failed to announce party presence", "error", err, "userId", userID)
+	}
+}
+
+// changed reloads a player's party and sends it to every member
+func (s *Service) changed(ctx context.Context, userID uuid.UUID) (*Party, error) {
+	party, err := s.party(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifier().Notify(party.userIDs(), game.Event{Type: EventPartyUpdated, Data: party})
+	return party, nil
+}
+
+// party loads a player's party and marks which members are online
+func (s *Service) party(ctx context.Context, userID uuid.UUID) (*Party, error) {
+	party, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	events := s.notifier()
+	for _, m := range party.Members {
+		m.Online = events.Online(m.UserID)
+	}
+	return party, nil
+}
+
+func (s *Service) notifier() Notifier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events
+}
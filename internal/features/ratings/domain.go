@@ -0,0 +1,118 @@
+package ratings
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Starting values of a player who has not played a rated match
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+)
+
+// Rating is a player's Glicko-2 rating in one queue
+type Rating struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Queue      string    `json:"queue" db:"queue"`
+	Rating     float64   `json:"rating" db:"rating"`
+	Deviation  float64   `json:"deviation" db:"deviation"`
+	Volatility float64   `json:"volatility" db:"volatility"`
+	Games      int       `json:"games" db:"games"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Default returns the rating of a player new to a queue
+func Default(userID uuid.UUID, queue string) *Rating {
+	return &Rating{
+		UserID:     userID,
+		Queue:      queue,
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// HistoryEntry is a player's rating right after one rated match
+type HistoryEntry struct {
+	MatchID    uuid.UUID `json:"match_id" db:"match_id"`
+	Queue      string    `json:"queue" db:"queue"`
+	Rating     float64   `json:"rating" db:"rating"`
+	Deviation  float64   `json:"deviation" db:"deviation"`
+	Volatility float64   `json:"volatility" db:"volatility"`
+	Delta      float64   `json:"delta" db:"delta"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// RatedMatch is an ended ranked match as needed to rate it
+type RatedMatch struct {
+	MatchID      uuid.UUID
+	Queue        string
+	WinnerUserID *uuid.UUID
+	WinnerTeam   *int
+	Players      []uuid.UUID
+	// Teams maps players of a team match to their team
+	Teams map[uuid.UUID]int
+}
+
+// rate computes every player's rating after a match from their ratings
+// before it. Each player is scored against every opponent: a win against
+// everyone for the winner, a loss to the winner, and a draw otherwise. In
+// team matches teammates are not scored against each other.
+func rate(match *RatedMatch, before map[uuid.UUID]*Rating) map[uuid.UUID]*Rating {
+	after := make(map[uuid.UUID]*Rating, len(match.Players))
+	for _, id := range match.Players {
+		var outcomes []Outcome
+		for _, other := range match.Players {
+			if other == id {
+				continue
+			}
+			if team := match.Teams[id]; team != 0 {
+				if match.Teams[other] == team {
+					continue
+				}
+				outcomes = append(outcomes, Outcome{Opponent: *before[other], Score: teamScore(match.WinnerTeam, team, match.Teams[other])})
+				continue
+			}
+			outcomes = append(outcomes, Outcome{Opponent: *before[other], Score: score(match.WinnerUserID, id, other)})
+		}
+
+		updated := Update(*before[id], outcomes)
+		updated.Games++
+		after[id] = &updated
+	}
+	return after
+}
+
+func score(winner *uuid.UUID, player, opponent uuid.UUID) float64 {
+	switch {
+	case winner == nil:
+		return 0.5
+	case *winner == player:
+		return 1
+	case *winner == opponent:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+func teamScore(winner *int, team, opponent int) float64 {
+	switch {
+	case winner == nil:
+		return 0.5
+	case *winner == team:
+		return 1
+	case *winner == opponent:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+var (
+	ErrMatchNotRatable = errors.New("match is not an ended ranked match awaiting rating")
+)
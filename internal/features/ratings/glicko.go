@@ -0,0 +1,98 @@
+package ratings
+
+import "math"
+
+// Glicko-2 system constants
+const (
+	// glickoScale converts between the Glicko and Glicko-2 scales
+	glickoScale = 173.7178
+	// tau constrains how much the volatility can change in one match
+	tau = 0.5
+	// convergence is the precision of the volatility iteration
+	convergence = 0.000001
+)
+
+// Outcome is one result against one opponent: 1 win, 0.5 draw, 0 loss
+type Outcome struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Update returns a rating after one rating period of outcomes, following
+// Glickman's "Example of the Glicko-2 system". Every match is its own period.
+func Update(r Rating, outcomes []Outcome) Rating {
+	mu := (r.Rating - DefaultRating) / glickoScale
+	phi := r.Deviation / glickoScale
+	sigma := r.Volatility
+
+	if len(outcomes) == 0 {
+		// Without games only the uncertainty grows
+		r.Deviation = math.Min(math.Sqrt(phi*phi+sigma*sigma)*glickoScale, DefaultDeviation)
+		return r
+	}
+
+	var vInv, sum float64
+	for _, o := range outcomes {
+		muJ := (o.Opponent.Rating - DefaultRating) / glickoScale
+		gJ := g(o.Opponent.Deviation / glickoScale)
+		e := expected(mu, muJ, gJ)
+		vInv += gJ * gJ * e * (1 - e)
+		sum += gJ * (o.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	sigma = newVolatility(phi, sigma, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	r.Rating = mu*glickoScale + DefaultRating
+	r.Deviation = phi * glickoScale
+	r.Volatility = sigma
+	return r
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// newVolatility solves for the new volatility with the Illinois algorithm
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > convergence {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
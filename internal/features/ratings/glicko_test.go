@@ -0,0 +1,160 @@
+package ratings
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func approx(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name               string
+		before             Rating
+		outcomes           []Outcome
+		wantRating, wantRD float64
+		wantVolatility     float64
+	}{
+		{
+			// Glickman, "Example of the Glicko-2 system", section 3
+			name:   "paper example",
+			before: Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			outcomes: []Outcome{
+				{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+				{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+				{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+			},
+			wantRating:     1464.06,
+			wantRD:         151.52,
+			wantVolatility: 0.05999,
+		},
+		{
+			name:           "no games only grows the deviation",
+			before:         Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			wantRating:     1500,
+			wantRD:         200.27,
+			wantVolatility: 0.06,
+		},
+		{
+			name:           "deviation never grows past the default",
+			before:         Rating{Rating: 1500, Deviation: DefaultDeviation, Volatility: 0.06},
+			wantRating:     1500,
+			wantRD:         DefaultDeviation,
+			wantVolatility: 0.06,
+		},
+		{
+			name:   "draw between equals keeps the rating",
+			before: Rating{Rating: 1500, Deviation: 350, Volatility: 0.06},
+			outcomes: []Outcome{
+				{Opponent: Rating{Rating: 1500, Deviation: 350, Volatility: 0.06}, Score: 0.5},
+			},
+			wantRating:     1500,
+			wantRD:         290.32,
+			wantVolatility: 0.06,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Update(tt.before, tt.outcomes)
+			if !approx(got.Rating, tt.wantRating, 0.01) {
+				t.Errorf("rating = %.4f, want %.2f", got.Rating, tt.wantRating)
+			}
+			if !approx(got.Deviation, tt.wantRD, 0.01) {
+				t.Errorf("deviation = %.4f, want %.2f", got.Deviation, tt.wantRD)
+			}
+			if !approx(got.Volatility, tt.wantVolatility, 0.00001) {
+				t.Errorf("volatility = %.6f, want %.5f", got.Volatility, tt.wantVolatility)
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	fresh := func(ids ...uuid.UUID) map[uuid.UUID]*Rating {
+		before := make(map[uuid.UUID]*Rating, len(ids))
+		for _, id := range ids {
+			before[id] = Default(id, "ranked")
+		}
+		return before
+	}
+	team := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		// match and the sign of each player's rating change
+		match *RatedMatch
+		want  map[uuid.UUID]int
+	}{
+		{
+			name:  "winner gains, loser drops",
+			match: &RatedMatch{Players: []uuid.UUID{a, b}, WinnerUserID: &a},
+			want:  map[uuid.UUID]int{a: 1, b: -1},
+		},
+		{
+			name:  "draw between equals",
+			match: &RatedMatch{Players: []uuid.UUID{a, b}},
+			want:  map[uuid.UUID]int{a: 0, b: 0},
+		},
+		{
+			name:  "free for all, one winner",
+			match: &RatedMatch{Players: []uuid.UUID{a, b, c}, WinnerUserID: &b},
+			want:  map[uuid.UUID]int{a: -1, b: 1, c: -1},
+		},
+		{
+			name: "teams",
+			match: &RatedMatch{
+				Players:    []uuid.UUID{a, b, c, d},
+				Teams:      map[uuid.UUID]int{a: 1, b: 2, c: 1, d: 2},
+				WinnerTeam: team(2),
+			},
+			want: map[uuid.UUID]int{a: -1, b: 1, c: -1, d: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := rate(tt.match, fresh(tt.match.Players...))
+			for id, want := range tt.want {
+				got := after[id]
+				if got.Games != 1 {
+					t.Errorf("games = %d, want 1", got.Games)
+				}
+				delta := got.Rating - DefaultRating
+				switch {
+				case want > 0 && delta <= 0, want < 0 && delta >= 0, want == 0 && !approx(delta, 0, 0.0001):
+					t.Errorf("rating change = %.2f, want sign %d", delta, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateTeammatesScoreAlike(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	before := map[uuid.UUID]*Rating{
+		a: Default(a, "teams_ranked"),
+		b: Default(b, "teams_ranked"),
+		c: Default(c, "teams_ranked"),
+		d: Default(d, "teams_ranked"),
+	}
+	winner := 1
+	match := &RatedMatch{
+		Players:    []uuid.UUID{a, b, c, d},
+		Teams:      map[uuid.UUID]int{a: 1, b: 2, c: 1, d: 2},
+		WinnerTeam: &winner,
+	}
+
+	after := rate(match, before)
+	if !approx(after[a].Rating, after[c].Rating, 0.0001) || !approx(after[b].Rating, after[d].Rating, 0.0001) {
+		t.Errorf("teammates rated apart: %.2f/%.2f and %.2f/%.2f", after[a].Rating, after[c].Rating, after[b].Rating, after[d].Rating)
+	}
+	if !approx(after[a].Rating-DefaultRating, DefaultRating-after[b].Rating, 0.0001) {
+		t.Errorf("gain %.2f does not mirror loss %.2f", after[a].Rating-DefaultRating, DefaultRating-after[b].Rating)
+	}
+}
@@ -0,0 +1,201 @@
+package ratings
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RatingRepository interface for data access
+type RatingRepository interface {
+	Get(ctx context.Context, userID uuid.UUID, queue string) (*Rating, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Rating, error)
+	History(ctx context.Context, userID uuid.UUID, queue string, limit int) ([]*HistoryEntry, error)
+	ListUnrated(ctx context.Context, limit int) ([]uuid.UUID, error)
+	RateMatch(ctx context.Context, matchID uuid.UUID) (map[uuid.UUID]*Rating, error)
+}
+
+// PostgresRatingRepository implements RatingRepository
+type PostgresRatingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new PostgreSQL rating repository
+func NewRepository(pool *pgxpool.Pool) RatingRepository {
+	return &PostgresRatingRepository{pool: pool}
+}
+
+const ratingColumns = `user_id, queue, rating, deviation, volatility, games, updated_at`
+
+func scanRating(row pgx.Row) (*Rating, error) {
+	var r Rating
+	err := row.Scan(&r.UserID, &r.Queue, &r.Rating, &r.Deviation, &r.Volatility, &r.Games, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Get returns a player's rating in a queue, or nil if they have none yet
+func (r *PostgresRatingRepository) Get(ctx context.Context, userID uuid.UUID, queue string) (*Rating, error) {
+	query := `SELECT ` + ratingColumns + ` FROM user_ratings WHERE user_id = $1 AND queue = $2`
+	rating, err := scanRating(r.pool.QueryRow(ctx, query, userID, queue))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rating, err
+}
+
+// ListByUser returns a player's ratings in every queue they played
+func (r *PostgresRatingRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Rating, error) {
+	query := `SELECT ` + ratingColumns + ` FROM user_ratings WHERE user_id = $1 ORDER BY queue`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Rating
+	for rows.Next() {
+		rating, err := scanRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rating)
+	}
+	return list, rows.Err()
+}
+
+// History returns a player's rating after each of their rated matches in a
+// queue, newest first
+func (r *PostgresRatingRepository) History(ctx context.Context, userID uuid.UUID, queue string, limit int) ([]*HistoryEntry, error) {
+	query := `SELECT match_id, queue, rating, deviation, volatility, delta, created_at
+		FROM rating_history
+		WHERE user_id = $1 AND queue = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3`
+	rows, err := r.pool.Query(ctx, query, userID, queue, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*HistoryEntry
+	for rows.Next() {
+		var e HistoryEntry
+		if err := rows.Scan(&e.MatchID, &e.Queue, &e.Rating, &e.Deviation, &e.Volatility, &e.Delta, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
+
+// ListUnrated returns ended ranked matches whose ratings were not applied yet,
+// oldest first
+func (r *PostgresRatingRepository) ListUnrated(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `SELECT id FROM matches
+		WHERE status = 'ended' AND ranked AND rated_at IS NULL
+		ORDER BY ended_at
+		LIMIT $1`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RateMatch applies the result of an ended ranked match to its players'
+// ratings and records their history, all in one transaction. The match row is
+// locked and marked rated, so a match is only ever rated once.
+func (r *PostgresRatingRepository) RateMatch(ctx context.Context, matchID uuid.UUID) (map[uuid.UUID]*Rating, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	match := RatedMatch{MatchID: matchID}
+	query := `SELECT queue, winner_user_id, winner_team FROM matches
+		WHERE id = $1 AND status = 'ended' AND ranked AND rated_at IS NULL
+		FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, matchID).Scan(&match.Queue, &match.WinnerUserID, &match.WinnerTeam); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMatchNotRatable
+		}
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT user_id, team FROM match_participants WHERE match_id = $1 AND NOT is_bot ORDER BY user_id`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	match.Teams = make(map[uuid.UUID]int)
+	for rows.Next() {
+		var (
+			id   uuid.UUID
+			team int
+		)
+		if err := rows.Scan(&id, &team); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		match.Players = append(match.Players, id)
+		match.Teams[id] = team
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Rows are locked in user order so concurrent matches cannot deadlock
+	before := make(map[uuid.UUID]*Rating, len(match.Players))
+	for _, id := range match.Players {
+		query := `SELECT ` + ratingColumns + ` FROM user_ratings WHERE user_id = $1 AND queue = $2 FOR UPDATE`
+		rating, err := scanRating(tx.QueryRow(ctx, query, id, match.Queue))
+		if errors.Is(err, pgx.ErrNoRows) {
+			rating, err = Default(id, match.Queue), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		before[id] = rating
+	}
+
+	after := rate(&match, before)
+
+	batch := &pgx.Batch{}
+	for _, id := range match.Players {
+		a := after[id]
+		batch.Queue(`INSERT INTO user_ratings (user_id, queue, rating, deviation, volatility, games, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (user_id, queue) DO UPDATE
+			SET rating = EXCLUDED.rating, deviation = EXCLUDED.deviation, volatility = EXCLUDED.volatility,
+				games = EXCLUDED.games, updated_at = EXCLUDED.updated_at`,
+			id, match.Queue, a.Rating, a.Deviation, a.Volatility, a.Games)
+		batch.Queue(`INSERT INTO rating_history (user_id, queue, match_id, rating, deviation, volatility, delta) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			id, match.Queue, matchID, a.Rating, a.Deviation, a.Volatility, a.Rating-before[id].Rating)
+	}
+	batch.Queue(`UPDATE matches SET rated_at = NOW() WHERE id = $1`, matchID)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
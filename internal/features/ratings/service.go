@@ -0,0 +1,114 @@
+package ratings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultHistorySize = 20
+	maxHistorySize     = 100
+	// sweepBatchSize caps the matches rated by one sweep
+	sweepBatchSize = 100
+	// rateTimeout bounds rating a match once it ended
+	rateTimeout = 10 * time.Second
+)
+
+// Service reads and updates player ratings
+type Service struct {
+	repo RatingRepository
+}
+
+// NewService creates a new rating service
+func NewService(repo RatingRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Get returns a player's rating in a queue, starting from the defaults if
+// they have not played a rated match in it yet
+func (s *Service) Get(ctx context.Context, userID uuid.UUID, queue string) (*Rating, error) {
+	rating, err := s.repo.Get(ctx, userID, queue)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if rating == nil {
+		return Default(userID, queue), nil
+	}
+	return rating, nil
+}
+
+// List returns a player's ratings in every queue they have played
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Rating, error) {
+	list, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return list, nil
+}
+
+// History returns a player's most recent rating changes in a queue
+func (s *Service) History(ctx context.Context, userID uuid.UUID, queue string, limit int) ([]*HistoryEntry, error) {
+	if limit <= 0 {
+		limit = defaultHistorySize
+	}
+	if limit > maxHistorySize {
+		limit = maxHistorySize
+	}
+
+	list, err := s.repo.History(ctx, userID, queue, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return list, nil
+}
+
+// MatchEnded implements game.EndListener by rating the match if it was ranked
+func (s *Service) MatchEnded(state *game.MatchState) {
+	ctx, cancel := context.WithTimeout(context.Background(), rateTimeout)
+	defer cancel()
+
+	if err := s.RateMatch(ctx, state.MatchID); err != nil && !errors.Is(err, ErrMatchNotRatable) {
+		// The startup sweep picks the match up again
+		slog.Error("Failed to rate match", "error", err, "matchId", state.MatchID)
+	}
+}
+
+// RateMatch applies the result of an ended ranked match to its players'
+// ratings; unranked and already rated matches return ErrMatchNotRatable
+func (s *Service) RateMatch(ctx context.Context, matchID uuid.UUID) error {
+	after, err := s.repo.RateMatch(ctx, matchID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range after {
+		slog.Info("Rating updated", "matchId", matchID, "userId", r.UserID, "queue", r.Queue, "rating", r.Rating, "deviation", r.Deviation)
+	}
+	return nil
+}
+
+// RatePending rates ranked matches that ended without their ratings being
+// applied, such as when the server stopped right after they ended
+func (s *Service) RatePending(ctx context.Context) (int, error) {
+	ids, err := s.repo.ListUnrated(ctx, sweepBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list unrated matches: %w", err)
+	}
+
+	rated := 0
+	for _, matchID := range ids {
+		if err := s.RateMatch(ctx, matchID); err != nil {
+			slog.Error("Failed to rate match", "error", err, "matchId", matchID)
+			continue
+		}
+		rated++
+	}
+	return rated, nil
+}
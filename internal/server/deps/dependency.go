@@ -1,6 +1,16 @@
 package deps
 
 import (
+	"time"
+
+	"demondoof-backend/internal/features/abilities"
+	"demondoof-backend/internal/features/compaction"
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/features/lobbies"
+	"demondoof-backend/internal/features/matches"
+	"demondoof-backend/internal/features/matchmaking"
+	"demondoof-backend/internal/features/parties"
+	"demondoof-backend/internal/features/ratings"
 	"demondoof-backend/internal/features/users"
 	"demondoof-backend/pkg/config"
 
@@ -13,20 +23,115 @@ type Dependencies struct {
 	Cfg         *config.Config
 	UserRepo    users.UserRepository
 	UserService *users.Service
+
+	AbilityRepo    abilities.AbilityRepository
+	AbilityService *abilities.Service
+
+	GameRepo    game.Repository
+	GameService *game.Service
+
+	MatchRepo    matches.MatchRepository
+	MatchService *matches.Service
+
+	CompactionRepo    compaction.CompactionRepository
+	CompactionService *compaction.Service
+
+	MatchmakingRepo    matchmaking.MatchmakingRepository
+	MatchmakingService *matchmaking.Service
+
+	RatingRepo    ratings.RatingRepository
+	RatingService *ratings.Service
+
+	LobbyRepo    lobbies.LobbyRepository
+	LobbyService *lobbies.Service
+
+	PartyRepo    parties.PartyRepository
+	PartyService *parties.Service
 }
 
 // Bootstrap initializes application dependencies ₍^. .^₎⟆
 func Bootstrap(pool *pgxpool.Pool, cfg *config.Config) (*Dependencies, error) {
 	// repositories
 	userRepo := users.NewRepository(pool)
+	abilityRepo := abilities.NewRepository(pool)
+	gameRepo := game.NewRepository(pool)
+	matchRepo := matches.NewRepository(pool)
+	compactionRepo := compaction.NewRepository(pool)
+	matchmakingRepo := matchmaking.NewRepository(pool)
+	ratingRepo := ratings.NewRepository(pool)
+	lobbyRepo := lobbies.NewRepository(pool)
+	partyRepo := parties.NewRepository(pool)
 
 	// services
 	userService := users.NewService(userRepo, cfg.JWTSecret)
+	abilityService := abilities.NewService(abilityRepo)
+	matchService := matches.NewService(matchRepo)
+	compactionService := compaction.NewService(compactionRepo, compaction.Config{
+		Interval:  time.Duration(cfg.CompactionIntervalSec) * time.Second,
+		Retention: time.Duration(cfg.SnapshotRetentionHours) * time.Hour,
+		BatchSize: cfg.CompactionBatchSize,
+	})
+	gameService := game.NewService(gameRepo, abilityService, game.Rules{
+		TurnTimeoutSec:         cfg.TurnTimeoutSec,
+		MaxConsecutiveTimeouts: cfg.MaxConsecutiveTimeouts,
+		MaxTurns:               cfg.MaxTurns,
+		DrawOfferCooldownTurns: cfg.DrawOfferCooldownTurns,
+		SpectatorDelayTurns:    cfg.SpectatorDelayTurns,
+		SpectatorDelaySec:      cfg.SpectatorDelaySec,
+		DisconnectGraceSec:     cfg.DisconnectGraceSec,
+		TimerPolicy:            game.TimerPolicy(cfg.DisconnectTimerPolicy),
+	})
+	ratingService := ratings.NewService(ratingRepo)
+	matchmakingService := matchmaking.NewService(matchmakingRepo, gameService, abilityService, ratingService, matchmaking.Config{
+		BotTimeout:         time.Duration(cfg.MatchmakingBotTimeoutSec) * time.Second,
+		StartingHP:         cfg.StartingHP,
+		StartingAP:         cfg.StartingAP,
+		RatingWindow:       float64(cfg.RatingWindow),
+		RatingWindowGrowth: float64(cfg.RatingWindowGrowth),
+		RatingWindowMax:    float64(cfg.RatingWindowMax),
+		AcceptTimeout:      time.Duration(cfg.AcceptTimeoutSec) * time.Second,
+		Cooldown:           time.Duration(cfg.QueueCooldownSec) * time.Second,
+		CooldownMax:        time.Duration(cfg.QueueCooldownMaxSec) * time.Second,
+	})
+
+	lobbyService := lobbies.NewService(lobbyRepo, gameService, abilityService, lobbies.Config{
+		StartingHP: cfg.StartingHP,
+		StartingAP: cfg.StartingAP,
+	})
+
+	partyService := parties.NewService(partyRepo, matchmakingService)
+
+	// Ranked matches are rated as soon as they end
+	gameService.AddEndListener(ratingService)
 
 	return &Dependencies{
 		Pool:        pool,
 		Cfg:         cfg,
 		UserRepo:    userRepo,
 		UserService: userService,
+
+		AbilityRepo:    abilityRepo,
+		AbilityService: abilityService,
+
+		GameRepo:    gameRepo,
+		GameService: gameService,
+
+		MatchRepo:    matchRepo,
+		MatchService: matchService,
+
+		CompactionRepo:    compactionRepo,
+		CompactionService: compactionService,
+
+		MatchmakingRepo:    matchmakingRepo,
+		MatchmakingService: matchmakingService,
+
+		RatingRepo:    ratingRepo,
+		RatingService: ratingService,
+
+		LobbyRepo:    lobbyRepo,
+		LobbyService: lobbyService,
+
+		PartyRepo:    partyRepo,
+		PartyService: partyService,
 	}, nil
 }
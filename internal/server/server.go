@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"demondoof-backend/pkg/config"
 	"demondoof-backend/pkg/middleware"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -24,6 +26,12 @@ import (
 type Server struct {
 	app  *fiber.App
 	port int
+	deps *deps.Dependencies
+
+	// metrics serves /debug/vars on its own internal address, apart from
+	// the public API
+	metrics     *fiber.App
+	metricsAddr string
 }
 
 // New creates a new server instance ₍^. .^₎⟆
@@ -65,11 +73,22 @@ func New(pool *pgxpool.Pool, cfg *config.Config) *Server {
 	app.Mount("/ws", ws.GetApp())
 
 	return &Server{
-		app:  app,
-		port: cfg.Port,
+		app:         app,
+		port:        cfg.Port,
+		deps:        deps,
+		metrics:     newMetricsApp(),
+		metricsAddr: cfg.MetricsAddr,
 	}
 }
 
+// newMetricsApp serves the runtime and game metrics (rebuilds, snapshot
+// divergences, compaction) on /debug/vars
+func newMetricsApp() *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(expvar.New())
+	return app
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.port)
@@ -77,6 +96,64 @@ func (s *Server) Start() error {
 	return s.app.Listen(addr)
 }
 
+// StartMetrics serves the metrics on the internal address; an empty
+// METRICS_ADDR disables them
+func (s *Server) StartMetrics() error {
+	if s.metricsAddr == "" {
+		return nil
+	}
+	slog.Info("Metrics starting", "address", s.metricsAddr)
+	return s.metrics.Listen(s.metricsAddr)
+}
+
+// Shutdown stops the public and the metrics listeners
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.metrics.ShutdownWithContext(ctx); err != nil {
+		slog.Warn("Failed to shut down metrics", "error", err)
+	}
+	return s.app.ShutdownWithContext(ctx)
+}
+
+// RecoverMatches resumes the matches that were active when the server last
+// stopped and discards the ones that were still waiting to start
+func (s *Server) RecoverMatches(ctx context.Context) error {
+	recovered, err := s.deps.GameService.Recover(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Active matches recovered", "count", recovered)
+
+	discarded, err := s.deps.MatchmakingService.DiscardPending(ctx)
+	if err != nil {
+		return err
+	}
+	if discarded > 0 {
+		slog.Info("Pending matches discarded", "count", discarded)
+	}
+	return nil
+}
+
+// StartJobs runs the background jobs until ctx is cancelled
+func (s *Server) StartJobs(ctx context.Context) {
+	go s.deps.CompactionService.Run(ctx)
+	go s.deps.MatchmakingService.Run(ctx)
+	go s.ratePendingMatches(ctx)
+}
+
+// ratePendingMatches applies the ratings of ranked matches that ended while
+// their rating could not be stored
+func (s *Server) ratePendingMatches(ctx context.Context) {
+	rated, err := s.deps.RatingService.RatePending(ctx)
+	if err != nil {
+		slog.Error("Failed to rate pending matches", "error", err)
+		return
+	}
+	if rated > 0 {
+		slog.Info("Pending ranked matches rated", "count", rated)
+	}
+}
+
 // GetApp returns the Fiber app instance
 func (s *Server) GetApp() *fiber.App {
 	return s.app
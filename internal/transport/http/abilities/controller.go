@@ -0,0 +1,70 @@
+package abilities
+
+import (
+	"errors"
+
+	"demondoof-backend/internal/features/abilities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	abilityService *abilities.Service
+	service        *Service
+	app            *fiber.App
+}
+
+func NewController(abilityService *abilities.Service) *Controller {
+	app := fiber.New()
+
+	ctrl := &Controller{
+		abilityService: abilityService,
+		service:        NewService(),
+		app:            app,
+	}
+
+	// Public catalog routes
+	ctrl.app.Get("/", ctrl.List)
+	ctrl.app.Get("/:id", ctrl.Get)
+
+	return ctrl
+}
+
+func (ctrl *Controller) GetApp() *fiber.App {
+	return ctrl.app
+}
+
+func (ctrl *Controller) List(c *fiber.Ctx) error {
+	list, version, err := ctrl.abilityService.List(c.Context())
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load abilities")
+	}
+
+	// Clients revalidate with If-None-Match and skip the body when nothing changed
+	if ctrl.service.NotModified(c, version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	response := ListResponse{Version: version, Abilities: make([]AbilityDTO, 0, len(list))}
+	for _, a := range list {
+		response.Abilities = append(response.Abilities, ctrl.service.ConvertToDTO(a))
+	}
+
+	return ctrl.service.RespondSuccess(c, response)
+}
+
+func (ctrl *Controller) Get(c *fiber.Ctx) error {
+	ability, version, err := ctrl.abilityService.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, abilities.ErrAbilityNotFound) {
+			return ctrl.service.RespondError(c, fiber.StatusNotFound, "Ability not found")
+		}
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load ability")
+	}
+
+	if ctrl.service.NotModified(c, version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctrl.service.RespondSuccess(c, ctrl.service.ConvertToDTO(ability))
+}
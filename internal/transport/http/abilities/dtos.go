@@ -0,0 +1,35 @@
+package abilities
+
+// AbilityDTO represents ability data for API responses
+type AbilityDTO struct {
+	ID                    string      `json:"id"`
+	Name                  string      `json:"name"`
+	BaseDamage            int         `json:"base_damage"`
+	APCost                int         `json:"ap_cost"`
+	Range                 int         `json:"range"`
+	AOERadius             *int        `json:"aoe_radius"`
+	AOEShape              string      `json:"aoe_shape"`
+	PerTurnLimit          *int        `json:"per_turn_limit"`
+	PerTargetPerTurnLimit *int        `json:"per_target_per_turn_limit"`
+	Effects               []EffectDTO `json:"effects"`
+}
+
+// EffectDTO represents a status effect applied by an ability
+type EffectDTO struct {
+	Kind    string `json:"kind"`
+	Turns   int    `json:"turns"`
+	Potency int    `json:"potency"`
+	OnSelf  bool   `json:"on_self"`
+}
+
+// ListResponse represents the ability catalog response
+type ListResponse struct {
+	Version   string       `json:"version"`
+	Abilities []AbilityDTO `json:"abilities"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
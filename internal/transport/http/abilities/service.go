@@ -0,0 +1,60 @@
+package abilities
+
+import (
+	"log/slog"
+
+	"demondoof-backend/internal/features/abilities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service handles HTTP transport logic for the ability catalog
+type Service struct{}
+
+// NewService creates a new abilities transport service
+func NewService() *Service {
+	return &Service{}
+}
+
+// ConvertToDTO converts an ability to its HTTP DTO
+func (s *Service) ConvertToDTO(a *abilities.Ability) AbilityDTO {
+	effects := make([]EffectDTO, 0, len(a.Effects))
+	for _, e := range a.Effects {
+		effects = append(effects, EffectDTO{Kind: string(e.Kind), Turns: e.Turns, Potency: e.Potency, OnSelf: e.OnSelf})
+	}
+
+	return AbilityDTO{
+		ID:                    a.ID,
+		Name:                  a.Name,
+		BaseDamage:            a.BaseDamage,
+		APCost:                a.APCost,
+		Range:                 a.Range,
+		AOERadius:             a.AOERadius,
+		AOEShape:              a.AOEShape,
+		PerTurnLimit:          a.PerTurnLimit,
+		PerTargetPerTurnLimit: a.PerTargetPerTurnLimit,
+		Effects:               effects,
+	}
+}
+
+// NotModified sets the ETag header and reports whether the client copy is current
+func (s *Service) NotModified(c *fiber.Ctx, version string) bool {
+	etag := `"` + version + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	return c.Get(fiber.HeaderIfNoneMatch) == etag
+}
+
+// RespondSuccess sends a successful JSON response
+func (s *Service) RespondSuccess(c *fiber.Ctx, data interface{}) error {
+	return c.Status(fiber.StatusOK).JSON(data)
+}
+
+// RespondError sends an error JSON response
+func (s *Service) RespondError(c *fiber.Ctx, statusCode int, message string) error {
+	slog.Warn("HTTP error response", "status", statusCode, "message", message)
+	return c.Status(statusCode).JSON(ErrorResponse{
+		Error:   "error",
+		Message: message,
+	})
+}
@@ -0,0 +1,116 @@
+package matches
+
+import (
+	"errors"
+
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/features/matches"
+	"demondoof-backend/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	matchService *matches.Service
+	gameService  *game.Service
+	service      *Service
+	app          *fiber.App
+}
+
+func NewController(matchService *matches.Service, gameService *game.Service) *Controller {
+	app := fiber.New()
+
+	ctrl := &Controller{
+		matchService: matchService,
+		gameService:  gameService,
+		service:      NewService(),
+		app:          app,
+	}
+
+	// Protected routes
+	ctrl.app.Use(middleware.RequireAuth())
+	ctrl.app.Get("/:id", ctrl.Get)
+	ctrl.app.Get("/:id/replay", ctrl.Replay)
+
+	return ctrl
+}
+
+func (ctrl *Controller) GetApp() *fiber.App {
+	return ctrl.app
+}
+
+func (ctrl *Controller) Get(c *fiber.Ctx) error {
+	usr, ok := middleware.GetUser(c)
+	if !ok || usr == nil {
+		return ctrl.service.RespondError(c, fiber.StatusUnauthorized, "Authentication required")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Invalid match ID")
+	}
+
+	match, err := ctrl.matchService.GetByID(c.Context(), id, usr.ID)
+	if err != nil {
+		if errors.Is(err, matches.ErrMatchNotFound) {
+			return ctrl.service.RespondError(c, fiber.StatusNotFound, "Match not found")
+		}
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load match")
+	}
+
+	return ctrl.service.RespondSuccess(c, ctrl.service.ConvertToDTO(match))
+}
+
+// Replay returns the ordered action timeline of an ended match together with
+// its initial participants
+func (ctrl *Controller) Replay(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Invalid match ID")
+	}
+
+	replay, err := ctrl.gameService.Replay(c.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, game.ErrMatchNotFound):
+			return ctrl.service.RespondError(c, fiber.StatusNotFound, "Match not found")
+		case errors.Is(err, game.ErrMatchNotEnded):
+			return ctrl.service.RespondError(c, fiber.StatusConflict, "Match has not ended yet")
+		case errors.Is(err, game.ErrCatalogVersionMissing):
+			return ctrl.service.RespondError(c, fiber.StatusConflict, "The abilities this match was played with are no longer available")
+		default:
+			return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load replay")
+		}
+	}
+
+	return ctrl.service.RespondSuccess(c, ctrl.service.ConvertReplayToDTO(replay))
+}
+
+// ListMine lists the authenticated user's matches, filtered by ?status= and
+// paginated with ?cursor= and ?limit=
+func (ctrl *Controller) ListMine(c *fiber.Ctx) error {
+	usr, ok := middleware.GetUser(c)
+	if !ok || usr == nil {
+		return ctrl.service.RespondError(c, fiber.StatusUnauthorized, "Authentication required")
+	}
+
+	page, err := ctrl.matchService.ListForUser(c.Context(), usr.ID, c.Query("status"), c.Query("cursor"), c.QueryInt("limit"))
+	if err != nil {
+		switch {
+		case errors.Is(err, matches.ErrInvalidStatus):
+			return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Status must be pending, active or ended")
+		case errors.Is(err, matches.ErrInvalidCursor):
+			return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Invalid cursor")
+		default:
+			return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load matches")
+		}
+	}
+
+	response := ListResponse{Matches: make([]MatchDTO, 0, len(page.Matches)), NextCursor: page.NextCursor}
+	for _, m := range page.Matches {
+		response.Matches = append(response.Matches, ctrl.service.ConvertToDTO(m))
+	}
+
+	return ctrl.service.RespondSuccess(c, response)
+}
@@ -0,0 +1,54 @@
+package matches
+
+import (
+	"time"
+
+	"demondoof-backend/internal/features/game"
+)
+
+// MatchDTO represents match data for API responses
+type MatchDTO struct {
+	ID           string           `json:"id"`
+	Status       string           `json:"status"`
+	StartedAt    time.Time        `json:"started_at"`
+	EndedAt      *time.Time       `json:"ended_at"`
+	WinnerUserID *string          `json:"winner_user_id"`
+	WinnerTeam   *int             `json:"winner_team,omitempty"`
+	EndReason    *string          `json:"end_reason"`
+	Participants []ParticipantDTO `json:"participants"`
+}
+
+// ParticipantDTO represents a match participant for API responses
+type ParticipantDTO struct {
+	UserID     string `json:"user_id"`
+	IsBot      bool   `json:"is_bot"`
+	StartingHP int    `json:"starting_hp"`
+	StartingAP int    `json:"starting_ap"`
+	StartX     int    `json:"start_x"`
+	StartY     int    `json:"start_y"`
+	Team       int    `json:"team,omitempty"`
+}
+
+// ListResponse represents a page of matches
+type ListResponse struct {
+	Matches    []MatchDTO `json:"matches"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ReplayDTO represents the recorded course of an ended match
+type ReplayDTO struct {
+	MatchID      string             `json:"match_id"`
+	Seed         int64              `json:"seed"`
+	Rules        game.Rules         `json:"rules"`
+	StartedAt    time.Time          `json:"started_at"`
+	Turns        int                `json:"turns"`
+	Result       *game.MatchResult  `json:"result"`
+	Participants []ParticipantDTO   `json:"participants"`
+	Timeline     []game.ReplayEvent `json:"timeline"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
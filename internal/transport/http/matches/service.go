@@ -0,0 +1,91 @@
+package matches
+
+import (
+	"log/slog"
+
+	"demondoof-backend/internal/features/game"
+	"demondoof-backend/internal/features/matches"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service handles HTTP transport logic for matches
+type Service struct{}
+
+// NewService creates a new matches transport service
+func NewService() *Service {
+	return &Service{}
+}
+
+// ConvertToDTO converts a match and its participants to the HTTP DTO
+func (s *Service) ConvertToDTO(m *matches.Match) MatchDTO {
+	dto := MatchDTO{
+		ID:           m.ID.String(),
+		Status:       m.Status,
+		StartedAt:    m.StartedAt,
+		EndedAt:      m.EndedAt,
+		WinnerTeam:   m.WinnerTeam,
+		EndReason:    m.EndReason,
+		Participants: make([]ParticipantDTO, 0, len(m.Participants)),
+	}
+	if m.WinnerUserID != nil {
+		winner := m.WinnerUserID.String()
+		dto.WinnerUserID = &winner
+	}
+
+	for _, p := range m.Participants {
+		dto.Participants = append(dto.Participants, ParticipantDTO{
+			UserID:     p.UserID.String(),
+			IsBot:      p.IsBot,
+			StartingHP: p.StartingHP,
+			StartingAP: p.StartingAP,
+			StartX:     p.StartX,
+			StartY:     p.StartY,
+			Team:       p.Team,
+		})
+	}
+
+	return dto
+}
+
+// ConvertReplayToDTO converts a match replay to the HTTP DTO
+func (s *Service) ConvertReplayToDTO(r *game.Replay) ReplayDTO {
+	dto := ReplayDTO{
+		MatchID:      r.MatchID.String(),
+		Seed:         r.Seed,
+		Rules:        r.Rules,
+		StartedAt:    r.StartedAt,
+		Turns:        r.Turns,
+		Result:       r.Result,
+		Participants: make([]ParticipantDTO, 0, len(r.Participants)),
+		Timeline:     r.Timeline,
+	}
+
+	for _, p := range r.Participants {
+		dto.Participants = append(dto.Participants, ParticipantDTO{
+			UserID:     p.UserID.String(),
+			IsBot:      p.IsBot,
+			StartingHP: p.StartingHP,
+			StartingAP: p.StartingAP,
+			StartX:     p.StartX,
+			StartY:     p.StartY,
+			Team:       p.Team,
+		})
+	}
+
+	return dto
+}
+
+// RespondSuccess sends a successful JSON response
+func (s *Service) RespondSuccess(c *fiber.Ctx, data interface{}) error {
+	return c.Status(fiber.StatusOK).JSON(data)
+}
+
+// RespondError sends an error JSON response
+func (s *Service) RespondError(c *fiber.Ctx, statusCode int, message string) error {
+	slog.Warn("HTTP error response", "status", statusCode, "message", message)
+	return c.Status(statusCode).JSON(ErrorResponse{
+		Error:   "error",
+		Message: message,
+	})
+}
@@ -0,0 +1,96 @@
+package ratings
+
+import (
+	"demondoof-backend/internal/features/ratings"
+	"demondoof-backend/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	ratingService *ratings.Service
+	service       *Service
+	app           *fiber.App
+}
+
+func NewController(ratingService *ratings.Service) *Controller {
+	app := fiber.New()
+
+	ctrl := &Controller{
+		ratingService: ratingService,
+		service:       NewService(),
+		app:           app,
+	}
+
+	// Protected routes; "me" stands for the authenticated user
+	ctrl.app.Use(middleware.RequireAuth())
+	ctrl.app.Get("/:id/ratings", ctrl.List)
+	ctrl.app.Get("/:id/ratings/:queue/history", ctrl.History)
+
+	return ctrl
+}
+
+func (ctrl *Controller) GetApp() *fiber.App {
+	return ctrl.app
+}
+
+// List returns a player's rating in every queue they have played
+func (ctrl *Controller) List(c *fiber.Ctx) error {
+	userID, ok := ctrl.userID(c)
+	if !ok {
+		return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	list, err := ctrl.ratingService.List(c.Context(), userID)
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load ratings")
+	}
+
+	response := ListResponse{Ratings: make([]RatingDTO, 0, len(list))}
+	for _, r := range list {
+		response.Ratings = append(response.Ratings, ctrl.service.ConvertToDTO(r))
+	}
+
+	return ctrl.service.RespondSuccess(c, response)
+}
+
+// History returns a player's rating after each of their latest rated matches
+// in a queue, newest first, limited by ?limit=
+func (ctrl *Controller) History(c *fiber.Ctx) error {
+	userID, ok := ctrl.userID(c)
+	if !ok {
+		return ctrl.service.RespondError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+	queue := c.Params("queue")
+
+	current, err := ctrl.ratingService.Get(c.Context(), userID, queue)
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load rating")
+	}
+	history, err := ctrl.ratingService.History(c.Context(), userID, queue, c.QueryInt("limit"))
+	if err != nil {
+		return ctrl.service.RespondError(c, fiber.StatusInternalServerError, "Failed to load rating history")
+	}
+
+	response := HistoryResponse{Current: ctrl.service.ConvertToDTO(current), History: make([]HistoryDTO, 0, len(history))}
+	for _, e := range history {
+		response.History = append(response.History, ctrl.service.ConvertHistoryToDTO(e))
+	}
+
+	return ctrl.service.RespondSuccess(c, response)
+}
+
+// userID resolves the :id parameter, where "me" is the authenticated user
+func (ctrl *Controller) userID(c *fiber.Ctx) (uuid.UUID, bool) {
+	if c.Params("id") == "me" {
+		usr, ok := middleware.GetUser(c)
+		if !ok || usr == nil {
+			return uuid.Nil, false
+		}
+		return usr.ID, true
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	return id, err == nil
+}
@@ -0,0 +1,41 @@
+package ratings
+
+import "time"
+
+// RatingDTO represents a player's rating in one queue for API responses
+type RatingDTO struct {
+	UserID     string     `json:"user_id"`
+	Queue      string     `json:"queue"`
+	Rating     float64    `json:"rating"`
+	Deviation  float64    `json:"deviation"`
+	Volatility float64    `json:"volatility"`
+	Games      int        `json:"games"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// HistoryDTO represents a rating change caused by one match
+type HistoryDTO struct {
+	MatchID    string    `json:"match_id"`
+	Rating     float64   `json:"rating"`
+	Deviation  float64   `json:"deviation"`
+	Volatility float64   `json:"volatility"`
+	Delta      float64   `json:"delta"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListResponse represents a player's ratings
+type ListResponse struct {
+	Ratings []RatingDTO `json:"ratings"`
+}
+
+// HistoryResponse represents a player's current rating in a queue and how it got there
+type HistoryResponse struct {
+	Current RatingDTO    `json:"current"`
+	History []HistoryDTO `json:"history"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,66 @@
+package ratings
+
+import (
+	"log/slog"
+	"math"
+
+	"demondoof-backend/internal/features/ratings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service handles HTTP transport logic for ratings
+type Service struct{}
+
+// NewService creates a new ratings transport service
+func NewService() *Service {
+	return &Service{}
+}
+
+// ConvertToDTO converts a rating to the HTTP DTO, rounding it for display
+func (s *Service) ConvertToDTO(r *ratings.Rating) RatingDTO {
+	dto := RatingDTO{
+		UserID:     r.UserID.String(),
+		Queue:      r.Queue,
+		Rating:     round(r.Rating, 1),
+		Deviation:  round(r.Deviation, 1),
+		Volatility: round(r.Volatility, 6),
+		Games:      r.Games,
+	}
+	// A default rating has never been stored
+	if !r.UpdatedAt.IsZero() {
+		dto.UpdatedAt = &r.UpdatedAt
+	}
+	return dto
+}
+
+// ConvertHistoryToDTO converts a rating history entry to the HTTP DTO
+func (s *Service) ConvertHistoryToDTO(e *ratings.HistoryEntry) HistoryDTO {
+	return HistoryDTO{
+		MatchID:    e.MatchID.String(),
+		Rating:     round(e.Rating, 1),
+		Deviation:  round(e.Deviation, 1),
+		Volatility: round(e.Volatility, 6),
+		Delta:      round(e.Delta, 1),
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// RespondSuccess sends a successful JSON response
+func (s *Service) RespondSuccess(c *fiber.Ctx, data interface{}) error {
+	return c.Status(fiber.StatusOK).JSON(data)
+}
+
+// RespondError sends an error JSON response
+func (s *Service) RespondError(c *fiber.Ctx, statusCode int, message string) error {
+	slog.Warn("HTTP error response", "status", statusCode, "message", message)
+	return c.Status(statusCode).JSON(ErrorResponse{
+		Error:   "error",
+		Message: message,
+	})
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"demondoof-backend/internal/server/deps"
+	abilitiesController "demondoof-backend/internal/transport/http/abilities"
 	authController "demondoof-backend/internal/transport/http/auth"
+	matchesController "demondoof-backend/internal/transport/http/matches"
+	ratingsController "demondoof-backend/internal/transport/http/ratings"
+	"demondoof-backend/pkg/middleware"
 )
 
 type HttpRouter struct {
@@ -32,9 +36,21 @@ func NewHttpRouter(deps *deps.Dependencies) *HttpRouter {
 
 	// Create auth controller with injected user service
 	authCtrl := authController.NewController(deps.UserService)
+	abilitiesCtrl := abilitiesController.NewController(deps.AbilityService)
+	matchesCtrl := matchesController.NewController(deps.MatchService, deps.GameService)
+	ratingsCtrl := ratingsController.NewController(deps.RatingService)
 
 	// Mount routes (Fiber Mount pattern)
 	v1.Mount("/auth", authCtrl.GetApp())
+	v1.Mount("/abilities", abilitiesCtrl.GetApp())
+	v1.Mount("/matches", matchesCtrl.GetApp())
+
+	// Routes scoped to the authenticated user
+	me := v1.Group("/users/me", middleware.RequireAuth())
+	me.Get("/matches", matchesCtrl.ListMine)
+
+	// Ratings of any user, with "me" for the authenticated one
+	v1.Mount("/users", ratingsCtrl.GetApp())
 
 	return router
 }
@@ -0,0 +1,106 @@
+package ws
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"demondoof-backend/internal/features/users"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/google/uuid"
+)
+
+const (
+	// sendBuffer is how many outbound messages may queue up for one connection
+	sendBuffer = 64
+	// writeWait bounds a single write to the socket
+	writeWait = 10 * time.Second
+)
+
+var (
+	ErrClientClosed = errors.New("websocket connection is closed")
+	ErrClientSlow   = errors.New("websocket connection is not keeping up")
+)
+
+// client is a single authenticated connection. Its read loop runs in the
+// handler; every write goes through the out queue and is performed by the
+// connection's own writer goroutine, so any goroutine may send to it.
+type client struct {
+	conn *websocket.Conn
+	user *users.User
+
+	out       chan Message
+	closed    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// rooms is guarded by the hub lock
+	rooms map[string]struct{}
+
+	// replay and spectating are owned by the read loop
+	replay     *replaySession
+	spectating map[uuid.UUID]struct{}
+}
+
+func newClient(conn *websocket.Conn, user *users.User) *client {
+	return &client{
+		conn:       conn,
+		user:       user,
+		out:        make(chan Message, sendBuffer),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+		rooms:      make(map[string]struct{}),
+		spectating: make(map[uuid.UUID]struct{}),
+	}
+}
+
+// send queues a message for the writer. A connection whose queue is full is
+// dropped rather than allowed to stall the sender.
+func (c *client) send(msg Message) error {
+	select {
+	case <-c.closed:
+		return ErrClientClosed
+	default:
+	}
+
+	select {
+	case c.out <- msg:
+		return nil
+	case <-c.closed:
+		return ErrClientClosed
+	default:
+		slog.Warn("Dropping slow WebSocket connection", "userId", c.user.ID, "type", msg.Type)
+		c.close()
+		return ErrClientSlow
+	}
+}
+
+// writeLoop delivers queued messages until the connection closes
+func (c *client) writeLoop() {
+	defer close(c.done)
+
+	for {
+		select {
+		case msg := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				slog.Warn("Error writing WebSocket message", "error", err, "type", msg.Type, "userId", c.user.ID)
+				c.close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// close stops the writer and unblocks the read loop; it is safe to call
+// from any goroutine and more than once
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,191 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// actionRequest is the payload of a "match.action" message
+type actionRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+	game.Action
+}
+
+// ErrorData is the payload of an "error" message
+type ErrorData struct {
+	RequestType string `json:"request_type"`
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+}
+
+func (c *client) sendError(requestType, code, message string) error {
+	return c.send(Message{
+		Type: "error",
+		Data: ErrorData{RequestType: requestType, Code: code, Message: message},
+	})
+}
+
+// sendGameError maps game errors to stable codes clients can switch on
+func (c *client) sendGameError(requestType string, err error) error {
+	var ruleErr *game.RuleError
+	switch {
+	case errors.As(err, &ruleErr):
+		return c.sendError(requestType, ruleErr.Code, ruleErr.Message)
+	case errors.Is(err, game.ErrMatchNotFound):
+		return c.sendError(requestType, "match_not_found", err.Error())
+	case errors.Is(err, game.ErrDuplicateAction):
+		return c.sendError(requestType, "duplicate_action", err.Error())
+	case errors.Is(err, game.ErrCatalogVersionMissing):
+		return c.sendError(requestType, "catalog_version_missing", err.Error())
+	default:
+		return c.sendError(requestType, "internal_error", "Internal server error")
+	}
+}
+
+// handleMatchAction submits a move, cast or end of turn for the connected player
+func handleMatchAction(ctx context.Context, c *client, gameService *game.Service, req Request) error {
+	var payload actionRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	// The acting user is always the authenticated one
+	payload.Action.UserID = c.user.ID
+
+	result, err := gameService.Submit(ctx, payload.MatchID, payload.Action)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	return c.send(Message{Type: "match.action.result", Data: result})
+}
+
+// matchRequest is the payload of match level messages that only name the match
+type matchRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+}
+
+// commandRequest is the payload of a surrender or draw message
+type commandRequest struct {
+	MatchID        uuid.UUID `json:"match_id"`
+	ClientActionID string    `json:"client_action_id,omitempty"`
+}
+
+// matchCommands maps match level message types to the engine action they submit
+var matchCommands = map[string]game.ActionType{
+	"match.surrender":    game.ActionSurrender,
+	"match.offer_draw":   game.ActionOfferDraw,
+	"match.accept_draw":  game.ActionAcceptDraw,
+	"match.decline_draw": game.ActionDeclineDraw,
+}
+
+// handleMatchCommand submits a surrender or draw negotiation step; these go
+// through the engine so they are logged and end the match like any action
+func handleMatchCommand(ctx context.Context, c *client, gameService *game.Service, req Request, actionType game.ActionType) error {
+	var payload commandRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	action := game.Action{Type: actionType, UserID: c.user.ID, ClientActionID: payload.ClientActionID}
+	result, err := gameService.Submit(ctx, payload.MatchID, action)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	return c.send(Message{Type: req.Type + ".result", Data: result})
+}
+
+// StateResponse is the full state of a running match, sent to players
+// (re)joining it
+type StateResponse struct {
+	State      *game.MatchState `json:"state"`
+	Deadline   time.Time        `json:"deadline"`
+	Spectators int              `json:"spectators"`
+}
+
+// handleMatchState returns the current state of a match the player takes part
+// in. Players are in the match room from its start, so asking for the state
+// is not needed to receive its events.
+func handleMatchState(c *client, gameService *game.Service, req Request) error {
+	var payload matchRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	state, deadline, err := gameService.State(payload.MatchID)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	if _, ok := state.Characters[c.user.ID]; !ok {
+		return c.sendGameError(req.Type, game.ErrNotParticipant)
+	}
+	// A match that ended in between simply has no spectators left
+	spectators, _ := gameService.Spectators(payload.MatchID)
+
+	return c.send(Message{Type: "match.state.result", Data: StateResponse{State: state, Deadline: deadline, Spectators: spectators}})
+}
+
+// resumeRequest is the payload of a "match.resume" message
+type resumeRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+	LastSeq int       `json:"last_seq"`
+}
+
+// handleMatchResume sends a reconnecting player the full match state and
+// every event after the last sequence number they saw
+func handleMatchResume(c *client, gameService *game.Service, req Request) error {
+	var payload resumeRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	resumption, err := gameService.Resume(payload.MatchID, c.user.ID, payload.LastSeq)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	return c.send(Message{Type: "match.resume.result", Data: resumption})
+}
+
+// targetsRequest is the payload of an "ability.targets" query
+type targetsRequest struct {
+	MatchID   uuid.UUID      `json:"match_id"`
+	AbilityID string         `json:"ability_id"`
+	From      *game.Position `json:"from,omitempty"`
+}
+
+// TargetsResponse lists the tiles an ability can be aimed at
+type TargetsResponse struct {
+	MatchID   uuid.UUID       `json:"match_id"`
+	AbilityID string          `json:"ability_id"`
+	From      *game.Position  `json:"from,omitempty"`
+	Targets   []game.Position `json:"targets"`
+}
+
+// handleAbilityTargets answers which tiles are legal targets so clients do not
+// have to duplicate range and line of sight rules
+func handleAbilityTargets(c *client, gameService *game.Service, req Request) error {
+	var payload targetsRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	targets, err := gameService.Targets(payload.MatchID, c.user.ID, payload.AbilityID, payload.From)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	if targets == nil {
+		targets = []game.Position{}
+	}
+
+	return c.send(Message{Type: "ability.targets.result", Data: TargetsResponse{
+		MatchID:   payload.MatchID,
+		AbilityID: payload.AbilityID,
+		From:      payload.From,
+		Targets:   targets,
+	}})
+}
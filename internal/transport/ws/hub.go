@@ -0,0 +1,241 @@
+package ws
+
+import (
+	"sync"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// UserRoom names the room holding every connection of one user
+func UserRoom(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// MatchRoom names the room of the players of a match
+func MatchRoom(matchID uuid.UUID) string {
+	return "match:" + matchID.String()
+}
+
+// SpectatorRoom names the room of the users watching a match
+func SpectatorRoom(matchID uuid.UUID) string {
+	return "spectate:" + matchID.String()
+}
+
+// LobbyRoom names the room of the members of a private lobby
+func LobbyRoom(code string) string {
+	return "lobby:" + code
+}
+
+// Hub routes messages to connections grouped in named rooms. Each connection
+// is in its user's room. Match, spectator and lobby rooms are joined by users
+// rather than connections, so their broadcasts reach every connection a
+// member has open, including ones opened after joining. All methods are safe
+// for concurrent use.
+type Hub struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*client]struct{}
+	// users holds the members of the rooms joined by user
+	users map[string]map[uuid.UUID]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		rooms: make(map[string]map[*client]struct{}),
+		users: make(map[string]map[uuid.UUID]struct{}),
+	}
+}
+
+// register adds a connection to its user room and reports whether it is the
+// user's first open connection
+func (h *Hub) register(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	first := len(h.rooms[UserRoom(c.user.ID)]) == 0
+	h.join(c, UserRoom(c.user.ID))
+	return first
+}
+
+// unregister removes a connection from every room and reports whether it
+// was the user's last open connection
+func (h *Hub) unregister(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for room := range c.rooms {
+		h.leave(c, room)
+	}
+	return len(h.rooms[UserRoom(c.user.ID)]) == 0
+}
+
+func (h *Hub) join(c *client, room string) {
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*client]struct{})
+		h.rooms[room] = members
+	}
+	members[c] = struct{}{}
+	c.rooms[room] = struct{}{}
+}
+
+func (h *Hub) leave(c *client, room string) {
+	delete(c.rooms, room)
+	delete(h.rooms[room], c)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// Join adds users to a room until they leave it or it is closed
+func (h *Hub) Join(room string, userIDs ...uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	members, ok := h.users[room]
+	if !ok {
+		members = make(map[uuid.UUID]struct{})
+		h.users[room] = members
+	}
+	for _, id := range userIDs {
+		members[id] = struct{}{}
+	}
+}
+
+// Leave removes users from a room
+func (h *Hub) Leave(room string, userIDs ...uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, id := range userIDs {
+		delete(h.users[room], id)
+	}
+	if len(h.users[room]) == 0 {
+		delete(h.users, room)
+	}
+}
+
+// CloseRoom removes every user from a room
+func (h *Hub) CloseRoom(room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.users, room)
+}
+
+// Size returns the number of connections in a room
+func (h *Hub) Size(room string) int {
+	return len(h.members([]string{room}))
+}
+
+// Online reports whether a user has at least one open connection
+func (h *Hub) Online(userID uuid.UUID) bool {
+	return h.Size(UserRoom(userID)) > 0
+}
+
+// Broadcast sends a message to every connection in the given rooms; a
+// connection in several of them receives it once
+func (h *Hub) Broadcast(msg Message, rooms ...string) {
+	for _, c := range h.members(rooms) {
+		// Failed sends drop the connection, whose read loop then cleans up
+		c.send(msg)
+	}
+}
+
+// SendToUser sends a message to every connection of a user
+func (h *Hub) SendToUser(userID uuid.UUID, msg Message) {
+	h.Broadcast(msg, UserRoom(userID))
+}
+
+// SendToUsers sends a message to every connection of each user
+func (h *Hub) SendToUsers(userIDs []uuid.UUID, msg Message) {
+	rooms := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		rooms[i] = UserRoom(id)
+	}
+	h.Broadcast(msg, rooms...)
+}
+
+// Notify implements the Notifier of matchmaking and parties, whose events
+// go to users picked one by one
+func (h *Hub) Notify(userIDs []uuid.UUID, event game.Event) {
+	h.SendToUsers(userIDs, eventMessage(event))
+}
+
+// JoinMatch implements game.Notifier
+func (h *Hub) JoinMatch(matchID uuid.UUID, audience game.Audience, userIDs ...uuid.UUID) {
+	h.Join(audienceRoom(matchID, audience), userIDs...)
+}
+
+// LeaveMatch implements game.Notifier
+func (h *Hub) LeaveMatch(matchID uuid.UUID, audience game.Audience, userIDs ...uuid.UUID) {
+	h.Leave(audienceRoom(matchID, audience), userIDs...)
+}
+
+// NotifyMatch implements game.Notifier
+func (h *Hub) NotifyMatch(matchID uuid.UUID, audience game.Audience, event game.Event) {
+	h.Broadcast(eventMessage(event), audienceRoom(matchID, audience))
+}
+
+// CloseMatch implements game.Notifier by closing both rooms of the match
+func (h *Hub) CloseMatch(matchID uuid.UUID) {
+	h.CloseRoom(MatchRoom(matchID))
+	h.CloseRoom(SpectatorRoom(matchID))
+}
+
+// JoinLobby implements lobbies.Notifier
+func (h *Hub) JoinLobby(code string, userIDs ...uuid.UUID) {
+	h.Join(LobbyRoom(code), userIDs...)
+}
+
+// LeaveLobby implements lobbies.Notifier
+func (h *Hub) LeaveLobby(code string, userIDs ...uuid.UUID) {
+	h.Leave(LobbyRoom(code), userIDs...)
+}
+
+// NotifyLobby implements lobbies.Notifier
+func (h *Hub) NotifyLobby(code string, event game.Event) {
+	h.Broadcast(eventMessage(event), LobbyRoom(code))
+}
+
+// CloseLobby implements lobbies.Notifier
+func (h *Hub) CloseLobby(code string) {
+	h.CloseRoom(LobbyRoom(code))
+}
+
+func audienceRoom(matchID uuid.UUID, audience game.Audience) string {
+	if audience == game.AudienceSpectators {
+		return SpectatorRoom(matchID)
+	}
+	return MatchRoom(matchID)
+}
+
+func eventMessage(event game.Event) Message {
+	return Message{Type: event.Type, Seq: event.Seq, Data: event.Data}
+}
+
+// members snapshots the distinct connections of the given rooms, those of
+// their member users included, so sends happen outside the lock
+func (h *Hub) members(rooms []string) []*client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[*client]struct{})
+	var targets []*client
+	add := func(room string) {
+		for c := range h.rooms[room] {
+			if _, ok := seen[c]; ok {
+				continue
+			}
+			seen[c] = struct{}{}
+			targets = append(targets, c)
+		}
+	}
+	for _, room := range rooms {
+		add(room)
+		for id := range h.users[room] {
+			add(UserRoom(id))
+		}
+	}
+	return targets
+}
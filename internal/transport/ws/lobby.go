@@ -0,0 +1,121 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"demondoof-backend/internal/features/lobbies"
+)
+
+// lobbyJoinRequest is the payload of a "lobby.join" message
+type lobbyJoinRequest struct {
+	Code string `json:"code"`
+}
+
+// lobbyReadyRequest is the payload of a "lobby.ready" message
+type lobbyReadyRequest struct {
+	Ready bool `json:"ready"`
+}
+
+// sendLobbyError maps lobby errors to stable codes clients can switch on
+func (c *client) sendLobbyError(requestType string, err error) error {
+	switch {
+	case errors.Is(err, lobbies.ErrLobbyNotFound):
+		return c.sendError(requestType, "lobby_not_found", err.Error())
+	case errors.Is(err, lobbies.ErrAlreadyInLobby):
+		return c.sendError(requestType, "already_in_lobby", err.Error())
+	case errors.Is(err, lobbies.ErrNotInLobby):
+		return c.sendError(requestType, "not_in_lobby", err.Error())
+	case errors.Is(err, lobbies.ErrLobbyFull):
+		return c.sendError(requestType, "lobby_full", err.Error())
+	case errors.Is(err, lobbies.ErrNotHost):
+		return c.sendError(requestType, "not_host", err.Error())
+	case errors.Is(err, lobbies.ErrLobbyStarting):
+		return c.sendError(requestType, "lobby_starting", err.Error())
+	case errors.Is(err, lobbies.ErrInMatch):
+		return c.sendError(requestType, "in_match", err.Error())
+	case errors.Is(err, lobbies.ErrInvalidSettings):
+		return c.sendError(requestType, "invalid_settings", err.Error())
+	case errors.Is(err, lobbies.ErrUnknownAbility):
+		return c.sendError(requestType, "unknown_ability", err.Error())
+	default:
+		return c.sendError(requestType, "internal_error", "Internal server error")
+	}
+}
+
+// handleLobbyCreate opens a private lobby hosted by the player; its invite
+// code is in the result
+func handleLobbyCreate(c *client, lobbyService *lobbies.Service, req Request) error {
+	lobby, err := lobbyService.Create(c.user.ID)
+	if err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.create.result", Data: lobby})
+}
+
+// handleLobbyJoin enters a lobby by invite code
+func handleLobbyJoin(c *client, lobbyService *lobbies.Service, req Request) error {
+	var payload lobbyJoinRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.Code == "" {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	lobby, err := lobbyService.Join(c.user.ID, payload.Code)
+	if err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.join.result", Data: lobby})
+}
+
+// handleLobbyLeave takes the player out of their lobby
+func handleLobbyLeave(c *client, lobbyService *lobbies.Service, req Request) error {
+	if err := lobbyService.Leave(c.user.ID); err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.leave.result", Data: map[string]bool{"left": true}})
+}
+
+// handleLobbyState returns the lobby the player is in
+func handleLobbyState(c *client, lobbyService *lobbies.Service, req Request) error {
+	lobby, err := lobbyService.Get(c.user.ID)
+	if err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.state.result", Data: lobby})
+}
+
+// handleLobbySettings lets the host change the settings of the match
+func handleLobbySettings(ctx context.Context, c *client, lobbyService *lobbies.Service, req Request) error {
+	var settings lobbies.Settings
+	if err := json.Unmarshal(req.Data, &settings); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	lobby, err := lobbyService.UpdateSettings(ctx, c.user.ID, settings)
+	if err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.settings.result", Data: lobby})
+}
+
+// handleLobbyReady confirms or withdraws the player's ready check; the
+// match starts once every member is ready, followed by "lobby.started"
+func handleLobbyReady(ctx context.Context, c *client, lobbyService *lobbies.Service, req Request) error {
+	var payload lobbyReadyRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	lobby, err := lobbyService.SetReady(ctx, c.user.ID, payload.Ready)
+	if err != nil {
+		return c.sendLobbyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "lobby.ready.result", Data: lobby})
+}
@@ -0,0 +1,143 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"demondoof-backend/internal/features/matchmaking"
+	"demondoof-backend/internal/features/parties"
+
+	"github.com/google/uuid"
+)
+
+// partyInviteRequest is the payload of a "party.invite" message
+type partyInviteRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// partyAnswerRequest is the payload of "party.accept" and "party.decline"
+type partyAnswerRequest struct {
+	PartyID uuid.UUID `json:"party_id"`
+}
+
+// sendPartyError maps party errors to stable codes clients can switch on;
+// queueing errors keep their matchmaking codes
+func (c *client) sendPartyError(requestType string, err error) error {
+	switch {
+	case errors.Is(err, parties.ErrNotInParty):
+		return c.sendError(requestType, "not_in_party", err.Error())
+	case errors.Is(err, parties.ErrAlreadyInParty):
+		return c.sendError(requestType, "already_in_party", err.Error())
+	case errors.Is(err, parties.ErrNotLeader):
+		return c.sendError(requestType, "not_party_leader", err.Error())
+	case errors.Is(err, parties.ErrPartyFull):
+		return c.sendError(requestType, "party_full", err.Error())
+	case errors.Is(err, parties.ErrInviteNotFound):
+		return c.sendError(requestType, "invite_not_found", err.Error())
+	case errors.Is(err, parties.ErrInviteSelf):
+		return c.sendError(requestType, "invite_self", err.Error())
+	case errors.Is(err, parties.ErrUserNotFound):
+		return c.sendError(requestType, "user_not_found", err.Error())
+	case errors.Is(err, parties.ErrMembersOffline):
+		return c.sendError(requestType, "members_offline", err.Error())
+	case errors.Is(err, matchmaking.ErrUnknownQueue), errors.Is(err, matchmaking.ErrAlreadyQueued),
+		errors.Is(err, matchmaking.ErrNotQueued), errors.Is(err, matchmaking.ErrInMatch),
+		errors.Is(err, matchmaking.ErrOnCooldown), errors.Is(err, matchmaking.ErrPartyTooLarge):
+		return c.sendQueueError(requestType, err)
+	default:
+		return c.sendError(requestType, "internal_error", "Internal server error")
+	}
+}
+
+// handlePartyState returns the player's party and the invites waiting for them
+func handlePartyState(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	state, err := partyService.Get(ctx, c.user.ID)
+	if err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.state.result", Data: state})
+}
+
+// handlePartyInvite invites a player to the sender's party, founding it if
+// needed; the invitee receives "party.invited"
+func handlePartyInvite(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	var payload partyInviteRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.UserID == uuid.Nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	party, err := partyService.Invite(ctx, c.user.ID, payload.UserID)
+	if err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.invite.result", Data: party})
+}
+
+// handlePartyAccept joins the party of an invite
+func handlePartyAccept(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	var payload partyAnswerRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.PartyID == uuid.Nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	party, err := partyService.Accept(ctx, c.user.ID, payload.PartyID)
+	if err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.accept.result", Data: party})
+}
+
+// handlePartyDecline turns down an invite
+func handlePartyDecline(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	var payload partyAnswerRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.PartyID == uuid.Nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	if err := partyService.Decline(ctx, c.user.ID, payload.PartyID); err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.decline.result", Data: payload})
+}
+
+// handlePartyLeave takes the player out of their party
+func handlePartyLeave(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	if err := partyService.Leave(ctx, c.user.ID); err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.leave.result", Data: map[string]bool{"left": true}})
+}
+
+// handlePartyQueue lets the leader queue the whole party; every member
+// receives "party.queued"
+func handlePartyQueue(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	var payload queueRequest
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return c.sendError(req.Type, "invalid_request", "Invalid message data")
+		}
+	}
+
+	status, err := partyService.Queue(ctx, c.user.ID, payload.Queue)
+	if err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.queue.result", Data: status})
+}
+
+// handlePartyQueueLeave takes the player's party out of the queue
+func handlePartyQueueLeave(ctx context.Context, c *client, partyService *parties.Service, req Request) error {
+	status, err := partyService.LeaveQueue(ctx, c.user.ID)
+	if err != nil {
+		return c.sendPartyError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "party.queue_leave.result", Data: status})
+}
@@ -0,0 +1,105 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"demondoof-backend/internal/features/matchmaking"
+
+	"github.com/google/uuid"
+)
+
+// queueRequest is the payload of a "queue.join" message
+type queueRequest struct {
+	Queue string `json:"queue"`
+}
+
+// proposalRequest is the payload of "match.accept" and "match.decline"
+type proposalRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+}
+
+// sendQueueError maps matchmaking errors to stable codes clients can switch on
+func (c *client) sendQueueError(requestType string, err error) error {
+	switch {
+	case errors.Is(err, matchmaking.ErrUnknownQueue):
+		return c.sendError(requestType, "unknown_queue", err.Error())
+	case errors.Is(err, matchmaking.ErrAlreadyQueued):
+		return c.sendError(requestType, "already_queued", err.Error())
+	case errors.Is(err, matchmaking.ErrNotQueued):
+		return c.sendError(requestType, "not_queued", err.Error())
+	case errors.Is(err, matchmaking.ErrInMatch):
+		return c.sendError(requestType, "in_match", err.Error())
+	case errors.Is(err, matchmaking.ErrOnCooldown):
+		return c.sendError(requestType, "queue_cooldown", err.Error())
+	case errors.Is(err, matchmaking.ErrNoProposal):
+		return c.sendError(requestType, "no_proposal", err.Error())
+	case errors.Is(err, matchmaking.ErrPartyTooLarge):
+		return c.sendError(requestType, "party_too_large", err.Error())
+	default:
+		return c.sendError(requestType, "internal_error", "Internal server error")
+	}
+}
+
+// handleQueueJoin puts the player in a matchmaking queue; "match.found" follows
+// once they are seated
+func handleQueueJoin(ctx context.Context, c *client, matchmakingService *matchmaking.Service, req Request) error {
+	var payload queueRequest
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return c.sendError(req.Type, "invalid_request", "Invalid message data")
+		}
+	}
+
+	status, err := matchmakingService.Join(ctx, c.user.ID, payload.Queue)
+	if err != nil {
+		return c.sendQueueError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "queue.join.result", Data: status})
+}
+
+// handleQueueLeave takes the player out of matchmaking
+func handleQueueLeave(c *client, matchmakingService *matchmaking.Service, req Request) error {
+	if err := matchmakingService.Leave(c.user.ID); err != nil {
+		return c.sendQueueError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "queue.leave.result", Data: matchmakingService.Status(c.user.ID)})
+}
+
+// handleQueueStatus reports the player's place in the queue
+func handleQueueStatus(c *client, matchmakingService *matchmaking.Service, req Request) error {
+	return c.send(Message{Type: "queue.status.result", Data: matchmakingService.Status(c.user.ID)})
+}
+
+// handleMatchAccept accepts a proposed match; "match.found" follows once
+// every player accepted
+func handleMatchAccept(ctx context.Context, c *client, matchmakingService *matchmaking.Service, req Request) error {
+	var payload proposalRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.MatchID == uuid.Nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	if err := matchmakingService.Accept(ctx, c.user.ID, payload.MatchID); err != nil {
+		return c.sendQueueError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "match.accept.result", Data: payload})
+}
+
+// handleMatchDecline refuses a proposed match, which puts the player on
+// queue cooldown
+func handleMatchDecline(c *client, matchmakingService *matchmaking.Service, req Request) error {
+	var payload proposalRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil || payload.MatchID == uuid.Nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	if err := matchmakingService.Decline(c.user.ID, payload.MatchID); err != nil {
+		return c.sendQueueError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "match.decline.result", Data: matchmakingService.Status(c.user.ID)})
+}
@@ -0,0 +1,316 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// maxReplayGap caps the real time waited between two replayed actions, so
+// turns that ran into their timeout do not stall playback
+const maxReplayGap = 3 * time.Second
+
+// replaySpeeds are the playback rates a replay can run at
+var replaySpeeds = map[int]bool{1: true, 2: true, 4: true, 8: true}
+
+// watchRequest is the payload of a "replay.watch" message
+type watchRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Speed   int       `json:"speed"`
+}
+
+// speedRequest is the payload of a "replay.speed" message
+type speedRequest struct {
+	Speed int `json:"speed"`
+}
+
+// seekRequest is the payload of a "replay.seek" message
+type seekRequest struct {
+	TurnNo int `json:"turn_no"`
+}
+
+// WatchResponse describes the replay that is about to stream
+type WatchResponse struct {
+	MatchID      uuid.UUID          `json:"match_id"`
+	Speed        int                `json:"speed"`
+	Turns        int                `json:"turns"`
+	Events       int                `json:"events"`
+	Rules        game.Rules         `json:"rules"`
+	Participants []game.Participant `json:"participants"`
+	Result       *game.MatchResult  `json:"result"`
+}
+
+// SnapshotEvent restarts the client's view of a replay at a turn
+type SnapshotEvent struct {
+	MatchID    uuid.UUID                `json:"match_id"`
+	TurnNo     int                      `json:"turn_no"`
+	Characters []game.CharacterSnapshot `json:"characters"`
+}
+
+// replayControl is a command from the read loop to a running replay
+type replayControl struct {
+	pause    bool
+	resume   bool
+	speed    int
+	seekTurn int
+	snapshot []game.CharacterSnapshot
+}
+
+// replaySession streams the timeline of one replay to a connection at a
+// chosen pace; it runs in its own goroutine and is steered over controls
+type replaySession struct {
+	c        *client
+	replay   *game.Replay
+	speed    int
+	controls chan replayControl
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// handleReplayWatch starts streaming a replay, replacing any replay the
+// connection was already watching
+func handleReplayWatch(ctx context.Context, c *client, gameService *game.Service, req Request) error {
+	var payload watchRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+	if payload.Speed == 0 {
+		payload.Speed = 1
+	}
+	if !replaySpeeds[payload.Speed] {
+		return c.sendError(req.Type, "invalid_speed", "Speed must be 1, 2, 4 or 8")
+	}
+
+	replay, err := gameService.Replay(ctx, payload.MatchID)
+	if err != nil {
+		if errors.Is(err, game.ErrMatchNotEnded) {
+			return c.sendError(req.Type, "match_not_ended", err.Error())
+		}
+		return c.sendGameError(req.Type, err)
+	}
+
+	c.stopReplay()
+	if err := c.send(Message{Type: "replay.watch.result", Data: WatchResponse{
+		MatchID:      replay.MatchID,
+		Speed:        payload.Speed,
+		Turns:        replay.Turns,
+		Events:       len(replay.Timeline),
+		Rules:        replay.Rules,
+		Participants: replay.Participants,
+		Result:       replay.Result,
+	}}); err != nil {
+		return err
+	}
+
+	c.replay = &replaySession{
+		c:        c,
+		replay:   replay,
+		speed:    payload.Speed,
+		controls: make(chan replayControl),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go c.replay.run()
+
+	return nil
+}
+
+// handleReplayControl pauses, resumes, re-paces or stops the running replay
+func handleReplayControl(c *client, req Request) error {
+	if c.replay == nil {
+		return c.sendError(req.Type, "replay_not_running", "No replay is being watched")
+	}
+
+	var ctl replayControl
+	switch req.Type {
+	case "replay.pause":
+		ctl.pause = true
+	case "replay.resume":
+		ctl.resume = true
+	case "replay.speed":
+		var payload speedRequest
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return c.sendError(req.Type, "invalid_request", "Invalid message data")
+		}
+		if !replaySpeeds[payload.Speed] {
+			return c.sendError(req.Type, "invalid_speed", "Speed must be 1, 2, 4 or 8")
+		}
+		ctl.speed = payload.Speed
+	case "replay.stop":
+		c.stopReplay()
+		return c.send(Message{Type: "replay.stop.result", Data: nil})
+	}
+
+	c.replay.control(ctl)
+	return c.send(Message{Type: req.Type + ".result", Data: nil})
+}
+
+// handleReplaySeek jumps the running replay to the start of a turn, restoring
+// the characters from the nearest snapshot instead of replaying from turn one
+func handleReplaySeek(ctx context.Context, c *client, gameService *game.Service, req Request) error {
+	if c.replay == nil {
+		return c.sendError(req.Type, "replay_not_running", "No replay is being watched")
+	}
+
+	var payload seekRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+	if payload.TurnNo < 1 || payload.TurnNo > c.replay.replay.Turns {
+		return c.sendError(req.Type, "invalid_turn", "Turn is outside of the replay")
+	}
+
+	snapshot, err := gameService.SnapshotAt(ctx, c.replay.replay, payload.TurnNo)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+
+	c.replay.control(replayControl{seekTurn: payload.TurnNo, snapshot: snapshot})
+	return nil
+}
+
+// stopReplay ends the replay the connection is watching, if any
+func (c *client) stopReplay() {
+	if c.replay == nil {
+		return
+	}
+	close(c.replay.stop)
+	<-c.replay.done
+	c.replay = nil
+}
+
+// control hands a command to the session unless it already stopped
+func (r *replaySession) control(ctl replayControl) {
+	select {
+	case r.controls <- ctl:
+	case <-r.done:
+	}
+}
+
+func (r *replaySession) run() {
+	defer close(r.done)
+
+	timeline := r.replay.Timeline
+	next, paused := 0, false
+	timer := time.NewTimer(r.delay(next))
+	defer timer.Stop()
+
+	for {
+		var tick <-chan time.Time
+		if !paused && next < len(timeline) {
+			tick = timer.C
+		}
+
+		select {
+		case <-r.stop:
+			return
+
+		case ctl := <-r.controls:
+			switch {
+			case ctl.pause:
+				paused = true
+			case ctl.resume:
+				paused = false
+			case ctl.speed != 0:
+				r.speed = ctl.speed
+			case ctl.seekTurn != 0:
+				var ok bool
+				if next, ok = r.seek(ctl); !ok {
+					return
+				}
+			}
+			resetTimer(timer, r.delay(next))
+
+		case <-tick:
+			if !r.emit(timeline[next]) {
+				return
+			}
+			next++
+			if next == len(timeline) {
+				if err := r.c.send(Message{Type: "replay.ended", Data: r.replay.Result}); err != nil {
+					return
+				}
+				continue
+			}
+			resetTimer(timer, r.delay(next))
+		}
+	}
+}
+
+// seek sends the snapshot a seek starts from and fast-forwards through the
+// actions between that snapshot and the requested turn. It returns the index
+// of the next action to play at normal pace.
+func (r *replaySession) seek(ctl replayControl) (int, bool) {
+	from := ctl.seekTurn
+	if len(ctl.snapshot) > 0 {
+		from = ctl.snapshot[0].TurnNo
+	}
+
+	if err := r.c.send(Message{Type: "replay.snapshot", Data: SnapshotEvent{
+		MatchID:    r.replay.MatchID,
+		TurnNo:     from,
+		Characters: ctl.snapshot,
+	}}); err != nil {
+		return 0, false
+	}
+
+	timeline := r.replay.Timeline
+	next := 0
+	for next < len(timeline) && timeline[next].TurnNo < from {
+		next++
+	}
+	for next < len(timeline) && timeline[next].TurnNo < ctl.seekTurn {
+		if !r.emit(timeline[next]) {
+			return 0, false
+		}
+		next++
+	}
+
+	return next, true
+}
+
+func (r *replaySession) emit(event game.ReplayEvent) bool {
+	if err := r.c.send(Message{Type: "replay.event", Data: event}); err != nil {
+		slog.Warn("Error sending replay event", "error", err, "matchId", r.replay.MatchID, "userId", r.c.user.ID)
+		return false
+	}
+	return true
+}
+
+// delay is how long to wait before playing the action at index i: the time
+// that passed before it in the match, capped and scaled by the speed
+func (r *replaySession) delay(i int) time.Duration {
+	timeline := r.replay.Timeline
+	if i >= len(timeline) {
+		return 0
+	}
+
+	previous := r.replay.StartedAt
+	if i > 0 {
+		previous = timeline[i-1].CreatedAt
+	}
+	gap := timeline[i].CreatedAt.Sub(previous)
+	if gap < 0 {
+		gap = 0
+	}
+	if gap > maxReplayGap {
+		gap = maxReplayGap
+	}
+	return gap / time.Duration(r.speed)
+}
+
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
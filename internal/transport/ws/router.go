@@ -1,8 +1,10 @@
 package ws
 
 import (
+	"context"
 	"demondoof-backend/internal/server/deps"
 	"demondoof-backend/pkg/middleware"
+	"encoding/json"
 	"log/slog"
 	"time"
 
@@ -16,13 +18,27 @@ type WebSocketRouter struct {
 
 type Message struct {
 	Type string      `json:"type"`
+	Seq  int         `json:"seq,omitempty"`
 	Data interface{} `json:"data"`
 }
 
-func NewWebSocketRouter(_ *deps.Dependencies) *WebSocketRouter {
+// Request is an inbound message whose data is decoded by its handler
+type Request struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func NewWebSocketRouter(deps *deps.Dependencies) *WebSocketRouter {
 	app := fiber.New()
 
-	app.Get("/", NewHandler())
+	// Game events are pushed to players through the hub
+	hub := NewHub()
+	deps.GameService.SetNotifier(hub)
+	deps.MatchmakingService.SetNotifier(hub)
+	deps.LobbyService.SetNotifier(hub)
+	deps.PartyService.SetNotifier(hub)
+
+	app.Get("/", NewHandler(deps, hub))
 
 	return &WebSocketRouter{app: app}
 }
@@ -31,7 +47,7 @@ func (r *WebSocketRouter) GetApp() *fiber.App {
 	return r.app
 }
 
-func NewHandler() fiber.Handler {
+func NewHandler(deps *deps.Dependencies, hub *Hub) fiber.Handler {
 	return websocket.New(func(c *websocket.Conn) {
 		// Get user from context (set by auth middleware) ₍^. .^₎⟆
 		user, ok := middleware.GetUserFromWebSocket(c)
@@ -43,13 +59,35 @@ func NewHandler() fiber.Handler {
 
 		slog.Info("WebSocket connection established", "userId", user.ID, "userEmail", user.Email)
 
+		cl := newClient(c, user)
+		go cl.writeLoop()
+		if hub.register(cl) {
+			deps.GameService.Connected(user.ID)
+			deps.PartyService.Connected(context.Background(), user.ID)
+		}
+
 		defer func() {
 			slog.Info("WebSocket connection closed", "userId", user.ID, "userEmail", user.Email)
-			c.Close()
+			if hub.unregister(cl) {
+				deps.GameService.Disconnected(user.ID)
+				// Nobody is left to tell about a found match; a match waiting
+				// to be accepted counts as declined
+				deps.MatchmakingService.Leave(user.ID)
+				deps.LobbyService.Leave(user.ID)
+				// Parties are kept; their members only see the player go offline
+				deps.PartyService.Disconnected(context.Background(), user.ID)
+			}
+			cl.stopReplay()
+			cl.stopSpectating(deps.GameService)
+			// The socket must not be written to once the handler returns
+			cl.close()
+			<-cl.done
 		}()
 
+		ctx := context.Background()
+
 		for {
-			var msg Message
+			var msg Request
 			if err := c.ReadJSON(&msg); err != nil {
 				slog.Warn("Error reading WebSocket message", "error", err, "userId", user.ID)
 				break
@@ -57,6 +95,7 @@ func NewHandler() fiber.Handler {
 
 			slog.Debug("Received WebSocket message", "type", msg.Type, "userId", user.ID)
 
+			var err error
 			switch msg.Type {
 			case "ping":
 				response := Message{
@@ -68,14 +107,74 @@ func NewHandler() fiber.Handler {
 						"userName":  user.Name,
 					},
 				}
-				if err := c.WriteJSON(response); err != nil {
-					slog.Warn("Error sending pong", "error", err, "userId", user.ID)
-					return
-				}
+				err = cl.send(response)
+			case "match.action":
+				err = handleMatchAction(ctx, cl, deps.GameService, msg)
+			case "match.state":
+				err = handleMatchState(cl, deps.GameService, msg)
+			case "match.resume":
+				err = handleMatchResume(cl, deps.GameService, msg)
+			case "ability.targets":
+				err = handleAbilityTargets(cl, deps.GameService, msg)
+			case "match.surrender", "match.offer_draw", "match.accept_draw", "match.decline_draw":
+				err = handleMatchCommand(ctx, cl, deps.GameService, msg, matchCommands[msg.Type])
+			case "queue.join":
+				err = handleQueueJoin(ctx, cl, deps.MatchmakingService, msg)
+			case "queue.leave":
+				err = handleQueueLeave(cl, deps.MatchmakingService, msg)
+			case "queue.status":
+				err = handleQueueStatus(cl, deps.MatchmakingService, msg)
+			case "match.accept":
+				err = handleMatchAccept(ctx, cl, deps.MatchmakingService, msg)
+			case "match.decline":
+				err = handleMatchDecline(cl, deps.MatchmakingService, msg)
+			case "lobby.create":
+				err = handleLobbyCreate(cl, deps.LobbyService, msg)
+			case "lobby.join":
+				err = handleLobbyJoin(cl, deps.LobbyService, msg)
+			case "lobby.leave":
+				err = handleLobbyLeave(cl, deps.LobbyService, msg)
+			case "lobby.state":
+				err = handleLobbyState(cl, deps.LobbyService, msg)
+			case "lobby.settings":
+				err = handleLobbySettings(ctx, cl, deps.LobbyService, msg)
+			case "lobby.ready":
+				err = handleLobbyReady(ctx, cl, deps.LobbyService, msg)
+			case "party.state":
+				err = handlePartyState(ctx, cl, deps.PartyService, msg)
+			case "party.invite":
+				err = handlePartyInvite(ctx, cl, deps.PartyService, msg)
+			case "party.accept":
+				err = handlePartyAccept(ctx, cl, deps.PartyService, msg)
+			case "party.decline":
+				err = handlePartyDecline(ctx, cl, deps.PartyService, msg)
+			case "party.leave":
+				err = handlePartyLeave(ctx, cl, deps.PartyService, msg)
+			case "party.queue":
+				err = handlePartyQueue(ctx, cl, deps.PartyService, msg)
+			case "party.queue_leave":
+				err = handlePartyQueueLeave(ctx, cl, deps.PartyService, msg)
+			case "spectate.join":
+				err = handleSpectateJoin(cl, deps.GameService, msg)
+			case "spectate.leave":
+				err = handleSpectateLeave(cl, deps.GameService, msg)
+			case "match.spectating":
+				err = handleMatchSpectating(ctx, cl, deps.GameService, msg)
+			case "replay.watch":
+				err = handleReplayWatch(ctx, cl, deps.GameService, msg)
+			case "replay.pause", "replay.resume", "replay.speed", "replay.stop":
+				err = handleReplayControl(cl, msg)
+			case "replay.seek":
+				err = handleReplaySeek(ctx, cl, deps.GameService, msg)
 			default:
 				// Ignore unknown message types for now
 				slog.Debug("Unknown message type", "type", msg.Type, "userId", user.ID)
 			}
+
+			if err != nil {
+				slog.Warn("Error sending WebSocket response", "error", err, "type", msg.Type, "userId", user.ID)
+				return
+			}
 		}
 	})
 }
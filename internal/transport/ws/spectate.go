@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+
+	"demondoof-backend/internal/features/game"
+
+	"github.com/google/uuid"
+)
+
+// spectatingRequest is the payload of a "match.spectating" message
+type spectatingRequest struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Enabled bool      `json:"enabled"`
+}
+
+// SpectateResponse is the delayed view a spectator starts from
+type SpectateResponse struct {
+	State      *game.MatchState `json:"state"`
+	Spectators int              `json:"spectators"`
+}
+
+// handleSpectateJoin starts a read-only, delayed view of a running match
+func handleSpectateJoin(c *client, gameService *game.Service, req Request) error {
+	var payload matchRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	// Joining twice on one connection restarts the view instead of counting twice
+	if _, ok := c.spectating[payload.MatchID]; ok {
+		gameService.Unspectate(payload.MatchID, c.user.ID)
+		delete(c.spectating, payload.MatchID)
+	}
+
+	state, err := gameService.Spectate(payload.MatchID, c.user.ID)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+	c.spectating[payload.MatchID] = struct{}{}
+
+	count, err := gameService.Spectators(payload.MatchID)
+	if err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "spectate.join.result", Data: SpectateResponse{State: state, Spectators: count}})
+}
+
+// handleSpectateLeave stops watching a match
+func handleSpectateLeave(c *client, gameService *game.Service, req Request) error {
+	var payload matchRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	if _, ok := c.spectating[payload.MatchID]; ok {
+		gameService.Unspectate(payload.MatchID, c.user.ID)
+		delete(c.spectating, payload.MatchID)
+	}
+
+	return c.send(Message{Type: "spectate.leave.result", Data: payload})
+}
+
+// handleMatchSpectating lets the host of a private match open or close it to spectators
+func handleMatchSpectating(ctx context.Context, c *client, gameService *game.Service, req Request) error {
+	var payload spectatingRequest
+	if err := json.Unmarshal(req.Data, &payload); err != nil {
+		return c.sendError(req.Type, "invalid_request", "Invalid message data")
+	}
+
+	if err := gameService.SetSpectating(ctx, payload.MatchID, c.user.ID, payload.Enabled); err != nil {
+		return c.sendGameError(req.Type, err)
+	}
+
+	return c.send(Message{Type: "match.spectating.result", Data: payload})
+}
+
+// stopSpectating leaves every match the connection was watching
+func (c *client) stopSpectating(gameService *game.Service) {
+	for matchID := range c.spectating {
+		gameService.Unspectate(matchID, c.user.ID)
+	}
+	c.spectating = nil
+}
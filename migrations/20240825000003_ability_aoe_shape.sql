@@ -0,0 +1,8 @@
+-- +goose Up
+-- Add AOE shape to abilities, existing abilities keep the Manhattan diamond
+ALTER TABLE abilities
+    ADD COLUMN aoe_shape TEXT NOT NULL DEFAULT 'diamond'
+    CHECK (aoe_shape IN ('diamond', 'square', 'line', 'cone'));
+
+-- +goose Down
+ALTER TABLE abilities DROP COLUMN IF EXISTS aoe_shape;
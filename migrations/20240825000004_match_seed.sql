@@ -0,0 +1,6 @@
+-- +goose Up
+-- Store the RNG seed of every match so replays reproduce the same rolls
+ALTER TABLE matches ADD COLUMN seed BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE matches DROP COLUMN IF EXISTS seed;
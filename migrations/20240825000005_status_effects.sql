@@ -0,0 +1,17 @@
+-- +goose Up
+-- Status effects applied by abilities, e.g. [{"kind":"burn","turns":2,"potency":3}]
+ALTER TABLE abilities ADD COLUMN effects JSONB NOT NULL DEFAULT '[]';
+
+-- Active status effects of a character at the start of each turn
+ALTER TABLE character_snapshots ADD COLUMN effects JSONB NOT NULL DEFAULT '[]';
+
+UPDATE abilities SET effects = '[{"kind":"burn","turns":2,"potency":3}]' WHERE id = 'fireball';
+
+INSERT INTO abilities (id, name, base_damage, ap_cost, range, aoe_radius, per_turn_limit, per_target_per_turn_limit, effects) VALUES
+('frost_bolt', 'Frost Bolt', 8, 3, 3, NULL, 1, NULL, '[{"kind":"slow","turns":2,"potency":2}]'),
+('shield_bash', 'Shield Bash', 6, 3, 1, NULL, 1, NULL, '[{"kind":"stun","turns":1,"potency":0},{"kind":"shield","turns":2,"potency":15,"on_self":true}]');
+
+-- +goose Down
+DELETE FROM abilities WHERE id IN ('frost_bolt', 'shield_bash');
+ALTER TABLE character_snapshots DROP COLUMN IF EXISTS effects;
+ALTER TABLE abilities DROP COLUMN IF EXISTS effects;
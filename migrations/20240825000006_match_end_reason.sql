@@ -0,0 +1,10 @@
+-- +goose Up
+-- Restrict end_reason to the reasons the game engine produces
+ALTER TABLE matches ADD CONSTRAINT matches_end_reason_check CHECK (
+    end_reason IS NULL OR end_reason IN (
+        'elimination', 'surrender', 'timeout_forfeit', 'abandoned', 'turn_cap_draw', 'mutual_draw'
+    )
+);
+
+-- +goose Down
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_end_reason_check;
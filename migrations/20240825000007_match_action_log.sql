@@ -0,0 +1,14 @@
+-- +goose Up
+-- Store what a match is rebuilt from: its rules, its turn order and the
+-- position of every action in the log
+ALTER TABLE matches ADD COLUMN rules JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE matches ADD COLUMN turn_order UUID[] NOT NULL DEFAULT '{}';
+ALTER TABLE match_actions ADD COLUMN seq INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_match_actions_match_seq ON match_actions(match_id, seq);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_match_actions_match_seq;
+ALTER TABLE match_actions DROP COLUMN IF EXISTS seq;
+ALTER TABLE matches DROP COLUMN IF EXISTS turn_order;
+ALTER TABLE matches DROP COLUMN IF EXISTS rules;
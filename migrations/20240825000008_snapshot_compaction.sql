@@ -0,0 +1,9 @@
+-- +goose Up
+-- Track which ended matches already had their snapshots compacted
+ALTER TABLE matches ADD COLUMN compacted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_matches_compaction ON matches(ended_at) WHERE status = 'ended' AND compacted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_matches_compaction;
+ALTER TABLE matches DROP COLUMN IF EXISTS compacted_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Remember which ability catalog a match was played with so exports can tell
+-- whether a re-simulation uses the same ability stats
+ALTER TABLE matches ADD COLUMN catalog_version TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE matches DROP COLUMN IF EXISTS catalog_version;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Private matches have a host who can close them to spectators
+ALTER TABLE matches ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE matches ADD COLUMN host_user_id UUID NULL;
+ALTER TABLE matches ADD COLUMN spectating_disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE matches DROP COLUMN IF EXISTS spectating_disabled;
+ALTER TABLE matches DROP COLUMN IF EXISTS host_user_id;
+ALTER TABLE matches DROP COLUMN IF EXISTS is_private;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Clients tag actions with their own id so retries are only applied once
+ALTER TABLE match_actions ADD COLUMN client_action_id TEXT NULL;
+ALTER TABLE match_actions ADD CONSTRAINT uq_match_actions_client_action UNIQUE (match_id, user_id, client_action_id);
+
+-- +goose Down
+ALTER TABLE match_actions DROP CONSTRAINT IF EXISTS uq_match_actions_client_action;
+ALTER TABLE match_actions DROP COLUMN IF EXISTS client_action_id;
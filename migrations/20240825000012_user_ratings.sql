@@ -0,0 +1,43 @@
+-- +goose Up
+-- Matches remember the queue they were made in; ranked ones are rated once
+ALTER TABLE matches ADD COLUMN queue TEXT NULL;
+ALTER TABLE matches ADD COLUMN ranked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE matches ADD COLUMN rated_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_matches_unrated ON matches(ended_at) WHERE status = 'ended' AND ranked AND rated_at IS NULL;
+
+-- Glicko-2 rating of each player per queue
+CREATE TABLE user_ratings (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    queue TEXT NOT NULL,
+    rating DOUBLE PRECISION NOT NULL DEFAULT 1500,
+    deviation DOUBLE PRECISION NOT NULL DEFAULT 350,
+    volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06,
+    games INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, queue)
+);
+
+-- Rating of each player after every rated match
+CREATE TABLE rating_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    queue TEXT NOT NULL,
+    match_id UUID NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    rating DOUBLE PRECISION NOT NULL,
+    deviation DOUBLE PRECISION NOT NULL,
+    volatility DOUBLE PRECISION NOT NULL,
+    delta DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (match_id, user_id)
+);
+
+CREATE INDEX idx_rating_history_user_queue ON rating_history(user_id, queue, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS rating_history;
+DROP TABLE IF EXISTS user_ratings;
+DROP INDEX IF EXISTS idx_matches_unrated;
+ALTER TABLE matches DROP COLUMN IF EXISTS rated_at;
+ALTER TABLE matches DROP COLUMN IF EXISTS ranked;
+ALTER TABLE matches DROP COLUMN IF EXISTS queue;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Players who decline or let a found match lapse wait before queueing again;
+-- the wait doubles with every offence until a day passes without one
+CREATE TABLE queue_cooldowns (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    offences INT NOT NULL DEFAULT 0,
+    last_offence_at TIMESTAMPTZ NOT NULL,
+    until TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS queue_cooldowns;
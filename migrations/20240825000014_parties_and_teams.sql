@@ -0,0 +1,37 @@
+-- +goose Up
+-- Team matches seat allies on the same team and are won by a team
+ALTER TABLE match_participants ADD COLUMN team INT NOT NULL DEFAULT 0;
+ALTER TABLE matches ADD COLUMN winner_team INT NULL;
+
+-- Parties queue together; each player is in at most one
+CREATE TABLE parties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leader_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE party_members (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    party_id UUID NOT NULL REFERENCES parties(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_party_members_party_id ON party_members(party_id, joined_at);
+
+CREATE TABLE party_invites (
+    party_id UUID NOT NULL REFERENCES parties(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (party_id, user_id)
+);
+
+CREATE INDEX idx_party_invites_user_id ON party_invites(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS party_invites;
+DROP TABLE IF EXISTS party_members;
+DROP TABLE IF EXISTS parties;
+ALTER TABLE matches DROP COLUMN IF EXISTS winner_team;
+ALTER TABLE match_participants DROP COLUMN IF EXISTS team;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The timer of the current turn, so a recovered match resumes with the time
+-- its player really had left: a deadline, or what was left on a paused clock
+ALTER TABLE matches ADD COLUMN clock_turn_no INT NULL;
+ALTER TABLE matches ADD COLUMN turn_deadline TIMESTAMPTZ NULL;
+ALTER TABLE matches ADD COLUMN turn_paused_ms BIGINT NULL;
+
+-- +goose Down
+ALTER TABLE matches DROP COLUMN IF EXISTS turn_paused_ms;
+ALTER TABLE matches DROP COLUMN IF EXISTS turn_deadline;
+ALTER TABLE matches DROP COLUMN IF EXISTS clock_turn_no;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Every version of the ability catalog a match was played with, so old matches
+-- are replayed with the abilities they actually used
+CREATE TABLE ability_catalog_versions (
+    version TEXT PRIMARY KEY,
+    abilities JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ability_catalog_versions;
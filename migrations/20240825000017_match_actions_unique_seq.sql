@@ -0,0 +1,20 @@
+-- +goose Up
+-- Every action of a match has its own seq; rebuilds, resume and action dedupe
+-- rely on that order. Actions logged before seq existed are numbered in the
+-- order they were written.
+UPDATE match_actions a SET seq = n.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY match_id ORDER BY created_at, id) AS seq
+    FROM match_actions
+    WHERE match_id IN (SELECT match_id FROM match_actions WHERE seq = 0)
+) n
+WHERE a.id = n.id;
+
+ALTER TABLE match_actions ALTER COLUMN seq DROP DEFAULT;
+DROP INDEX IF EXISTS idx_match_actions_match_seq;
+ALTER TABLE match_actions ADD CONSTRAINT uq_match_actions_seq UNIQUE (match_id, seq);
+
+-- +goose Down
+ALTER TABLE match_actions DROP CONSTRAINT IF EXISTS uq_match_actions_seq;
+CREATE INDEX IF NOT EXISTS idx_match_actions_match_seq ON match_actions(match_id, seq);
+ALTER TABLE match_actions ALTER COLUMN seq SET DEFAULT 0;
@@ -11,11 +11,30 @@ import (
 
 type Config struct {
 	Port                     int    `envconfig:"PORT" default:"8080" `
+	MetricsAddr              string `envconfig:"METRICS_ADDR" default:"127.0.0.1:9090"`
 	DatabaseURL              string `envconfig:"DATABASE_URL" required:"true"`
 	JWTPublicKey             string `envconfig:"JWT_PUBLIC_KEY"`
 	JWTSecret                string `envconfig:"JWT_SECRET"`
 	MatchmakingBotTimeoutSec int    `envconfig:"MATCHMAKING_BOT_TIMEOUT_SEC" default:"30"`
+	StartingHP               int    `envconfig:"STARTING_HP" default:"100"`
+	StartingAP               int    `envconfig:"STARTING_AP" default:"6"`
+	RatingWindow             int    `envconfig:"MATCHMAKING_RATING_WINDOW" default:"100"`
+	RatingWindowGrowth       int    `envconfig:"MATCHMAKING_RATING_WINDOW_GROWTH" default:"10"`
+	RatingWindowMax          int    `envconfig:"MATCHMAKING_RATING_WINDOW_MAX" default:"600"`
+	AcceptTimeoutSec         int    `envconfig:"MATCHMAKING_ACCEPT_TIMEOUT_SEC" default:"15"`
+	QueueCooldownSec         int    `envconfig:"MATCHMAKING_COOLDOWN_SEC" default:"60"`
+	QueueCooldownMaxSec      int    `envconfig:"MATCHMAKING_COOLDOWN_MAX_SEC" default:"1800"`
 	TurnTimeoutSec           int    `envconfig:"TURN_TIMEOUT_SEC" default:"45"`
+	MaxConsecutiveTimeouts   int    `envconfig:"MAX_CONSECUTIVE_TIMEOUTS" default:"3"`
+	MaxTurns                 int    `envconfig:"MAX_TURNS" default:"100"`
+	DrawOfferCooldownTurns   int    `envconfig:"DRAW_OFFER_COOLDOWN_TURNS" default:"4"`
+	SpectatorDelayTurns      int    `envconfig:"SPECTATOR_DELAY_TURNS" default:"2"`
+	SpectatorDelaySec        int    `envconfig:"SPECTATOR_DELAY_SEC" default:"0"`
+	DisconnectGraceSec       int    `envconfig:"DISCONNECT_GRACE_SEC" default:"60"`
+	DisconnectTimerPolicy    string `envconfig:"DISCONNECT_TIMER_POLICY" default:"continue"`
+	CompactionIntervalSec    int    `envconfig:"SNAPSHOT_COMPACTION_INTERVAL_SEC" default:"3600"`
+	SnapshotRetentionHours   int    `envconfig:"SNAPSHOT_RETENTION_HOURS" default:"168"`
+	CompactionBatchSize      int    `envconfig:"SNAPSHOT_COMPACTION_BATCH_SIZE" default:"100"`
 	LogLevel                 string `envconfig:"LOG_LEVEL" default:"info"`
 }
 
@@ -48,6 +67,16 @@ func Load() (*AppConfig, error) {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
+	if cfg.DisconnectTimerPolicy != "continue" && cfg.DisconnectTimerPolicy != "pause" {
+		return nil, fmt.Errorf("DISCONNECT_TIMER_POLICY must be continue or pause")
+	}
+
+	// A paused clock only restarts when the player returns, so their slot
+	// cannot be held forever as well
+	if cfg.DisconnectTimerPolicy == "pause" && cfg.DisconnectGraceSec <= 0 {
+		return nil, fmt.Errorf("DISCONNECT_GRACE_SEC must be positive with DISCONNECT_TIMER_POLICY=pause")
+	}
+
 	// Validate JWT secret is not default in production
 	if cfg.JWTSecret == "your-super-secret-jwt-key-change-this-in-production" {
 		fmt.Fprintf(os.Stderr, "WARNING: Using default JWT secret in production is insecure\n")